@@ -0,0 +1,319 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// audit.go is an append-only, JSONL activity log of what botconfig
+// actually emitted, so "what config was live when I raided X" or "why did
+// NoBeanie flip off yesterday" can be answered without an external tool.
+// The record shape is modeled on jfa-go's Activity struct: a typed
+// ActivityType enum, a Source field, and an indexable ID.
+
+// ActivityType enumerates what kind of event a log entry records.
+type ActivityType string
+
+const (
+	ActivityEmit              ActivityType = "Emit"
+	ActivityIncludeSkipped    ActivityType = "IncludeSkipped"
+	ActivityValidationFailure ActivityType = "ValidationFailure"
+	ActivityRuleMatched       ActivityType = "RuleMatched"
+)
+
+// sourceHash pairs a contributing file with its content hash, so a later
+// `sources <game>` query can tell whether a file changed between two runs
+// without re-reading it.
+type sourceHash struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// activity is one line in the audit log.
+type activity struct {
+	ID        int64        `json:"id"`
+	Type      ActivityType `json:"type"`
+	Timestamp time.Time    `json:"timestamp"`
+	Game      string       `json:"game,omitempty"`
+	Weekday   string       `json:"weekday,omitempty"`
+	Date      string       `json:"date,omitempty"`
+	Invoker   string       `json:"invoker,omitempty"`
+	Source    string       `json:"source,omitempty"`
+	Sources   []sourceHash `json:"sources,omitempty"`
+	Diff      []string     `json:"diff,omitempty"`
+	Config    *config      `json:"config,omitempty"`
+	Detail    string       `json:"detail,omitempty"`
+}
+
+func auditDir() string {
+	return *configRoot + "audit\\"
+}
+
+// auditLogPath rotates the log daily by naming it after the date, so old
+// days can be pruned wholesale by retention.
+func auditLogPath(t time.Time) string {
+	return fmt.Sprintf("%s%s.jsonl", auditDir(), t.Format("2006-01-02"))
+}
+
+// logActivity fills in ID/Timestamp if unset and appends a to today's (or
+// a.Timestamp's) audit log file, then prunes logs older than
+// --auditRetentionDays. A no-op when --audit=false.
+func logActivity(a activity) {
+	if !*auditEnabled {
+		return
+	}
+	if a.Timestamp.IsZero() {
+		a.Timestamp = time.Now()
+	}
+	if a.ID == 0 {
+		a.ID = a.Timestamp.UnixNano()
+	}
+
+	path := auditLogPath(a.Timestamp)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		slog.Debug("Error creating audit log dir: " + err.Error())
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		slog.Debug("Error opening audit log: " + err.Error())
+		return
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(a)
+	if err != nil {
+		slog.Debug("Error marshaling audit entry: " + err.Error())
+		return
+	}
+	f.Write(append(line, '\n'))
+
+	rotateAuditLogs(a.Timestamp)
+}
+
+// rotateAuditLogs removes audit log files dated further back than
+// --auditRetentionDays.
+func rotateAuditLogs(now time.Time) {
+	cutoff := now.AddDate(0, 0, -*auditRetentionDays)
+
+	entries, err := os.ReadDir(auditDir())
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+			continue
+		}
+		dateStr := strings.TrimSuffix(e.Name(), ".jsonl")
+		t, err := time.Parse("2006-01-02", dateStr)
+		if err == nil && t.Before(cutoff) {
+			os.Remove(filepath.Join(auditDir(), e.Name()))
+		}
+	}
+}
+
+// readActivities loads every entry from every (non-rotated-away) audit log
+// file, oldest first.
+func readActivities() []activity {
+	entries, err := os.ReadDir(auditDir())
+	if err != nil {
+		return nil
+	}
+
+	all := []activity{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(auditDir(), e.Name()))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			var a activity
+			if err := json.Unmarshal([]byte(line), &a); err == nil {
+				all = append(all, a)
+			}
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Timestamp.Before(all[j].Timestamp) })
+	return all
+}
+
+// lastEmit returns the most recent Emit activity recorded for game, if any.
+func lastEmit(game string) *activity {
+	all := readActivities()
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].Type == ActivityEmit && all[i].Game == game {
+			return &all[i]
+		}
+	}
+	return nil
+}
+
+// diffConfigs reports one "field: old -> new" entry per field that changed
+// between old and new. A nil old means "nothing to diff against".
+func diffConfigs(old *config, new *config) []string {
+	if old == nil {
+		return nil
+	}
+
+	diffs := []string{}
+	t := reflect.TypeOf(config{})
+	ov := reflect.ValueOf(*old)
+	nv := reflect.ValueOf(*new)
+
+	for i := 0; i < t.NumField(); i++ {
+		of := ov.Field(i).Interface()
+		nf := nv.Field(i).Interface()
+		if !reflect.DeepEqual(of, nf) {
+			jsonName := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", jsonName, formatDiffValue(of), formatDiffValue(nf)))
+		}
+	}
+
+	return diffs
+}
+
+// formatDiffValue renders a config field value for diffConfigs, dereferencing
+// pointer fields (e.g. *int) so the diff shows the value rather than an
+// address; a nil pointer prints as "unset".
+func formatDiffValue(v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "unset"
+		}
+		return fmt.Sprintf("%v", rv.Elem().Interface())
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// hashSources reads each source file through activeSource and returns its
+// path paired with a SHA256 of its contents.
+func hashSources(paths []string) []sourceHash {
+	hashes := make([]sourceHash, 0, len(paths))
+	for _, p := range paths {
+		data, found, err := activeSource.Read(p)
+		if err != nil || !found {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		hashes = append(hashes, sourceHash{Path: p, SHA256: hex.EncodeToString(sum[:])})
+	}
+	return hashes
+}
+
+// recordEmit logs an Emit activity for a freshly built config, diffed
+// against the last Emit recorded for the same game.
+func recordEmit(game, weekday, date string, sources []string, c *config) {
+	prev := lastEmit(game)
+	var prevConfig *config
+	if prev != nil {
+		prevConfig = prev.Config
+	}
+
+	logActivity(activity{
+		Type:     ActivityEmit,
+		Game:     game,
+		Weekday:  weekday,
+		Date:     date,
+		Invoker:  *invoker,
+		Sources:  hashSources(sources),
+		Diff:     diffConfigs(prevConfig, c),
+		Config:   c,
+	})
+}
+
+// runAuditQuery backs --auditQuery: "last", "diff <game> <n>", or
+// "sources <game>". Returns false if query wasn't a recognized form.
+func runAuditQuery(query string) bool {
+	args := strings.Fields(query)
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "last":
+		all := readActivities()
+		if len(all) == 0 {
+			fmt.Println("No audit log entries found.")
+			return true
+		}
+		printActivity(all[len(all)-1])
+		return true
+
+	case "diff":
+		if len(args) < 3 {
+			fmt.Println("Usage: --auditQuery=\"diff <game> <n>\"")
+			return true
+		}
+		game := args[1]
+		emits := emitsForGame(game)
+		n := len(emits) - 1 - atoiOrZero(args[2])
+		if n < 0 || n >= len(emits) {
+			fmt.Printf("No emission %s back for %s.\n", args[2], game)
+			return true
+		}
+		printActivity(emits[n])
+		return true
+
+	case "sources":
+		if len(args) < 2 {
+			fmt.Println("Usage: --auditQuery=\"sources <game>\"")
+			return true
+		}
+		last := lastEmit(args[1])
+		if last == nil {
+			fmt.Printf("No emission recorded for %s.\n", args[1])
+			return true
+		}
+		for _, s := range last.Sources {
+			fmt.Printf("%s  %s\n", s.SHA256, s.Path)
+		}
+		return true
+	}
+
+	return false
+}
+
+func emitsForGame(game string) []activity {
+	all := readActivities()
+	emits := []activity{}
+	for _, a := range all {
+		if a.Type == ActivityEmit && a.Game == game {
+			emits = append(emits, a)
+		}
+	}
+	return emits
+}
+
+func atoiOrZero(s string) int {
+	n := 0
+	fmt.Sscanf(s, "%d", &n)
+	return n
+}
+
+func printActivity(a activity) {
+	out, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		slog.Debug("Error marshaling activity: " + err.Error())
+		return
+	}
+	fmt.Println(string(out))
+}
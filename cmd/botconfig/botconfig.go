@@ -0,0 +1,1000 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	debug      = flag.Bool("debug", false, "Print debugging info.")
+	game       = flag.String("game", "", "The game we are looking up.")
+	configRoot = flag.String(
+		"configRoot",
+		"G:\\My Drive\\Streaming\\Chatbot\\twitch_configs\\",
+		"Root folder where configs are found.",
+	)
+	writeJSONFile = flag.Bool("writeJSONFile", true, "Write a JSON file?")
+	outFile       = flag.String(
+		"outFile",
+		"D:\\Temp\\twitch_config.json",
+		"The output file we write merged configs to.",
+	)
+	writeSchema = flag.Bool("writeSchema", false, "Write a schema file?")
+	schemaFile  = flag.String(
+		"schemaFile",
+		"G:\\My Drive\\Streaming\\Chatbot\\twitch_configs\\schema.json",
+		"The schema file used to validate configs.",
+	)
+	onCall       = flag.Bool("oncall", false, "Am I oncall for work?")
+	dayOverride  = flag.String("dayOverride", "", "Manually set day for testing.")
+	dateOverride = flag.String("dateOverride", "", "Manually set date for testing.")
+	strict       = flag.Bool(
+		"strict",
+		false,
+		"Treat schema validation failures as fatal instead of warning.",
+	)
+	explainMerge = flag.Bool(
+		"explainMerge",
+		false,
+		"Print which source file contributed each field in the merged config.",
+	)
+	// Populated as mergeConfigs runs; only tracked when --explainMerge is set.
+	fieldSources = map[string]string{}
+	serve        = flag.Bool(
+		"serve",
+		false,
+		"Run as a long-lived daemon serving merged configs over HTTP instead of exiting after one run.",
+	)
+	serveAddr = flag.String(
+		"serveAddr",
+		"127.0.0.1:8089",
+		"Address to listen on when --serve is set.",
+	)
+	serveSocket = flag.String(
+		"socket",
+		"",
+		"Unix socket path to listen on when --serve is set, instead of --serveAddr.",
+	)
+	heartbeatSec = flag.Int(
+		"heartbeatSec",
+		15,
+		"Seconds between SSE heartbeat frames sent to /watch subscribers.",
+	)
+	pollIntervalSec = flag.Int(
+		"pollIntervalSec",
+		2,
+		"Seconds between configRoot polls in --serve mode; a backstop for day/date rollovers and filesystems the fsnotify watch can't see, not the primary change-detection path.",
+	)
+	grpcAddr = flag.String(
+		"grpcAddr",
+		"127.0.0.1:8090",
+		"Address for the gRPC ConfigService to listen on when --serve is set.",
+	)
+	source = flag.String(
+		"source",
+		"file",
+		"ConfigSource backend to read configs from: file, kv, or git.",
+	)
+	kvFile = flag.String(
+		"kvFile",
+		"",
+		"Path to the kv ConfigSource's backing JSON file (used with --source=kv).",
+	)
+	kvImport = flag.String(
+		"kvImport",
+		"",
+		"Import every *.json under this directory into --kvFile, then exit.",
+	)
+	kvExport = flag.String(
+		"kvExport",
+		"",
+		"Export every key in --kvFile as *.json under this directory, then exit.",
+	)
+	gitRepo = flag.String(
+		"gitRepo",
+		"",
+		"Repository URL to clone/pull configs from (used with --source=git).",
+	)
+	gitLocalPath = flag.String(
+		"gitLocalPath",
+		"",
+		"Local working tree for --gitRepo.",
+	)
+	gitPullIntervalSec = flag.Int(
+		"gitPullIntervalSec",
+		60,
+		"Seconds between git pulls when --source=git.",
+	)
+	nowOverride = flag.String(
+		"now",
+		"",
+		"RFC3339 timestamp overriding the current moment for day/date/month "+
+			"layering and rules.json predicates. Supersedes --dayOverride/"+
+			"--dateOverride for new use; both are still honored on top of it.",
+	)
+	auditEnabled = flag.Bool(
+		"audit",
+		true,
+		"Append an audit log entry for this run.",
+	)
+	auditRetentionDays = flag.Int(
+		"auditRetentionDays",
+		30,
+		"Days of daily-rotated audit logs to keep.",
+	)
+	invoker = flag.String(
+		"invoker",
+		"manual",
+		"Who invoked this run: streamerbot, manual, or scheduler. Recorded in the audit log.",
+	)
+	auditQuery = flag.String(
+		"auditQuery",
+		"",
+		"Run an audit query instead of merging configs: last, \"diff <game> <n>\", or \"sources <game>\".",
+	)
+	defaultTags  = []string{
+		"FirstPlaythrough",
+		"NoBackseating",
+	}
+	// Default costs of LP.
+	defaultLPTalkingCost = int(250)
+	defaultLPGameCost    = defaultLPTalkingCost * 2
+	// A list of all include files read by filename to avoid processing duplicates.
+	// Mostly as a cheap backstop to prevent a recursive loop of includes.
+	includesSeen = map[string]bool{}
+	// We now set this within StreamerBot based on which programs are running.
+	vtuberSoftware = flag.String(
+		"vtuberSoftware",
+		defaultVTuberSoftware,
+		"Which VTuber Software we set tags for.",
+	)
+	validVTuberSoftware = map[string]bool{
+		"None":      true,
+		"Veadotube": true,
+		"VNyan":     true,
+		"VTS":       true,
+	}
+	defaultVTuberSoftware = "VNyan"
+	// PandaSign is looser than VTuberSoftware today; these are just the
+	// variants we've actually drawn so far.
+	validPandaSign = map[string]bool{
+		"default": true,
+		"none":    true,
+		"custom":  true,
+	}
+)
+
+type config struct {
+	// Includes
+	Include string `json:"include"`
+	// Stream Settings
+	StreamTags     []string `json:"streamtags" schema:"maxItems=10" merge:"append-unique"`
+	TitleSuffix    string   `json:"titlesuffix" merge:"concat-sep= | "`
+	VTuberSoftware string   `json:"vtubersoftware" schema:"enum=None|Veadotube|VNyan|VTS" merge:"override"`
+	// Model Options
+	VNyanOutfit string `json:"vnyanoutfit" merge:"override"`
+	// Overlays
+	DeathCounter  bool   `json:"deathcounter" merge:"or"`
+	DeskCam       bool   `json:"deskcam" merge:"or"`
+	GamePad       bool   `json:"gamepad" merge:"or"`
+	OrpaxMemorial bool   `json:"orpaxmemorial" merge:"or"`
+	PandaSign     string `json:"pandasign" schema:"enum=default|none|custom" merge:"default-sentinel=default"`
+	Streamathon   bool   `json:"streamathon" merge:"or"`
+	Uptime        bool   `json:"uptime" merge:"or"`
+	// Other Functions
+	OutfitPoll    bool     `json:"outfitpoll" merge:"and"`
+	SongRequests  bool     `json:"songrequests" merge:"or"`
+	Collaborators []string `json:"collaborators" merge:"append-unique"`
+	// Rewards
+	BambooRequestCost int  `json:"bamboorequestcost" schema:"min=0,max=250" merge:"max"`
+	BedTime           bool `json:"bedtime" merge:"or"`
+	ChosenOne         bool `json:"chosenone" merge:"and"`
+	CreepyTime        bool `json:"creepytime" merge:"or"`
+	JibberJabbey      bool `json:"jibberjabbey" merge:"and"`
+	LPGameCost        int  `json:"lpgamecost" schema:"min=0,max=500" merge:"default-sentinel=500"`
+	LPTalkingCost     int  `json:"lptalkingcost" schema:"min=0,max=500" merge:"default-sentinel=250"`
+	NameAThing        bool `json:"nameathing" merge:"or"`
+	NoBeanie          bool `json:"nobeanie" merge:"or"`
+	RaidRoulette      bool `json:"raidroulette" merge:"and"`
+	// Commands
+	// Bot Functions
+	Modlist        bool `json:"modlist" merge:"or"`
+	NotifyInterval int  `json:"notifyinterval" schema:"min=0,max=60" merge:"min"`
+	// Control
+	// Note that GameFound serves the dual purpose to communicate to StreamerBot
+	// if we have a config for the game as well as to signal if we've found a
+	// config file here so we don't need to merge "empty" configs.
+	// EndHour/EndMinute are pointers so an explicit "minute 0" in a later
+	// config can be told apart from "not set" and still override an earlier
+	// non-zero value; a bare int can't carry that distinction.
+	EndHour           *int   `json:"endhour,omitempty" schema:"min=0,max=23" merge:"override"`
+	EndMinute         *int   `json:"endminute,omitempty" schema:"min=0,max=59" merge:"override"`
+	GameFound         bool   `json:"gamefound" merge:"and"`
+	GameName          string `json:"gamename" merge:"keep-first"`
+	SanitizedGameName string `json:"sanitizedgamename" merge:"keep-first"`
+	OnCall            bool   `json:"oncall" merge:"or"`
+	PauseableGame     bool   `json:"pauseablegame" merge:"and"`
+	YTGameInTitle     bool   `json:"ytgameintitle" merge:"and"`
+}
+
+func newConfig() *config {
+	// For setting non-standard default values.
+	return &config{
+		BambooRequestCost: 20,
+		ChosenOne:         true,
+		GameFound:         true,
+		JibberJabbey:      true,
+		LPGameCost:        defaultLPGameCost,
+		LPTalkingCost:     defaultLPTalkingCost,
+		NotifyInterval:    5,
+		OutfitPoll:        true,
+		PandaSign:         "default",
+		PauseableGame:     true,
+		RaidRoulette:      true,
+		YTGameInTitle:     true,
+	}
+}
+
+// fieldRule captures the `schema:"..."` constraints declared on a config
+// field so writeSchemaFile and validateConfig can share one source of truth.
+type fieldRule struct {
+	jsonName string
+	enum     []string
+	min      *float64
+	max      *float64
+	maxItems *int
+}
+
+// configFieldRules parses the `schema` struct tags on config into fieldRules.
+func configFieldRules() []fieldRule {
+	rules := []fieldRule{}
+
+	t := reflect.TypeOf(config{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("schema")
+		if tag == "" {
+			continue
+		}
+
+		rule := fieldRule{jsonName: strings.Split(f.Tag.Get("json"), ",")[0]}
+		for _, part := range strings.Split(tag, ",") {
+			kv := strings.SplitN(part, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "enum":
+				rule.enum = strings.Split(kv[1], "|")
+			case "min":
+				if v, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					rule.min = &v
+				}
+			case "max":
+				if v, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					rule.max = &v
+				}
+			case "maxItems":
+				if v, err := strconv.Atoi(kv[1]); err == nil {
+					rule.maxItems = &v
+				}
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// validateConfig checks a raw decoded config file against configFieldRules
+// and returns one human-readable violation per offending field, each
+// prefixed with a JSON pointer to the field.
+func validateConfig(raw map[string]any) []string {
+	violations := []string{}
+
+	for _, rule := range configFieldRules() {
+		v, ok := raw[rule.jsonName]
+		if !ok {
+			continue
+		}
+		ptr := "/" + rule.jsonName
+
+		if rule.enum != nil {
+			if s, ok := v.(string); ok && s != "" && !slices.Contains(rule.enum, s) {
+				violations = append(violations, fmt.Sprintf(
+					"%s: %q is not one of %s", ptr, s, strings.Join(rule.enum, "|"),
+				))
+			}
+		}
+
+		if rule.min != nil || rule.max != nil {
+			if n, ok := v.(float64); ok {
+				if rule.min != nil && n < *rule.min {
+					violations = append(violations, fmt.Sprintf("%s: %v is below minimum %v", ptr, n, *rule.min))
+				}
+				if rule.max != nil && n > *rule.max {
+					violations = append(violations, fmt.Sprintf("%s: %v is above maximum %v", ptr, n, *rule.max))
+				}
+			}
+		}
+
+		if rule.maxItems != nil {
+			if arr, ok := v.([]any); ok && len(arr) > *rule.maxItems {
+				violations = append(violations, fmt.Sprintf("%s: has %d items, maxItems is %d", ptr, len(arr), *rule.maxItems))
+			}
+		}
+	}
+
+	return violations
+}
+
+func readFromFile(f string) *config {
+	c := newConfig()
+
+	data, found, err := activeSource.Read(f)
+	if err != nil {
+		slog.Debug("Error loading config:", err.Error(), err)
+		c.GameFound = false
+		return c
+	}
+	if !found {
+		c.GameFound = false
+		return c
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err == nil {
+		if violations := validateConfig(raw); len(violations) > 0 {
+			for _, v := range violations {
+				slog.Error("Schema validation failed", "file", f, "violation", v)
+			}
+			logActivity(activity{
+				Type:   ActivityValidationFailure,
+				Source: f,
+				Detail: strings.Join(violations, "; "),
+			})
+			if *strict {
+				slog.Error("Refusing to merge invalid config file (--strict set): " + f)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		slog.Debug("Error parsing config:", err.Error(), err)
+	}
+
+	return c
+}
+
+func (c *config) writeToFile(f string) {
+	// Write the merged data to a new JSON file.
+	outputFile, err := os.Create(f)
+	if err != nil {
+		slog.Debug("Error creating config file:", err.Error(), err)
+	}
+	defer outputFile.Close()
+
+	encoder := json.NewEncoder(outputFile)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(c)
+	if err != nil {
+		slog.Debug("Error writing config file:", err.Error(), err)
+	}
+}
+
+func removeDuplicateStr(strSlice []string) []string {
+	allKeys := make(map[string]bool)
+	list := []string{}
+	for _, item := range strSlice {
+		// Remove spaces otherwise Twitch will reject.
+		item := strings.ReplaceAll(item, " ", "")
+
+		if !allKeys[item] {
+			list = append(list, item)
+			allKeys[item] = true
+		}
+	}
+	return list
+}
+
+// applyMergeStrategy mutates cf in place according to the strategy declared
+// in a field's `merge:"..."` tag, using nf as the incoming value. Strategies
+// taking a parameter encode it after an "=" (e.g. "default-sentinel=250").
+func applyMergeStrategy(cf reflect.Value, nf reflect.Value, tag string) {
+	strategy, param, _ := strings.Cut(tag, "=")
+
+	switch strategy {
+	case "override":
+		switch cf.Kind() {
+		case reflect.String:
+			if nf.String() != "" {
+				cf.SetString(nf.String())
+			}
+		case reflect.Int:
+			if nf.Int() != 0 {
+				cf.SetInt(nf.Int())
+			}
+		case reflect.Ptr:
+			if !nf.IsNil() {
+				cf.Set(nf)
+			}
+		}
+
+	case "keep-first":
+		// No-op: whatever c already had wins.
+
+	case "min":
+		if nf.Int() < cf.Int() {
+			cf.SetInt(nf.Int())
+		}
+
+	case "max":
+		if nf.Int() > cf.Int() {
+			cf.SetInt(nf.Int())
+		}
+
+	case "and":
+		cf.SetBool(cf.Bool() && nf.Bool())
+
+	case "or":
+		cf.SetBool(cf.Bool() || nf.Bool())
+
+	case "append-unique":
+		merged := removeDuplicateStr(append(
+			cf.Interface().([]string), nf.Interface().([]string)...,
+		))
+		cf.Set(reflect.ValueOf(merged))
+
+	case "concat-sep":
+		if nf.String() == "" {
+			break
+		}
+		if cf.String() != "" {
+			cf.SetString(cf.String() + param + nf.String())
+		} else {
+			cf.SetString(nf.String())
+		}
+
+	case "default-sentinel":
+		switch cf.Kind() {
+		case reflect.String:
+			if nf.String() != param {
+				cf.SetString(nf.String())
+			}
+		case reflect.Int:
+			sentinel, _ := strconv.ParseInt(param, 10, 64)
+			if nf.Int() != sentinel {
+				cf.SetInt(nf.Int())
+			}
+		}
+	}
+}
+
+// mergeStrategyDescription renders a field's raw `merge:"..."` tag as a
+// sentence for the generated schema, so config authors can see how their
+// overrides will combine without reading applyMergeStrategy's source.
+func mergeStrategyDescription(tag string) string {
+	strategy, param, _ := strings.Cut(tag, "=")
+
+	switch strategy {
+	case "override":
+		return "Overrides any prior value once set; the last config that sets it wins."
+	case "keep-first":
+		return "Keeps whichever config set it first; later configs cannot change it."
+	case "min":
+		return "Resolves to the smallest value set across all configs."
+	case "max":
+		return "Resolves to the largest value set across all configs."
+	case "and":
+		return "True only if every config that sets this field sets it true."
+	case "or":
+		return "True if any config sets this field true."
+	case "append-unique":
+		return "Combines every config's list into one, de-duplicated, instead of replacing it."
+	case "concat-sep":
+		return fmt.Sprintf("Concatenates every config's value together, joined by %q.", param)
+	case "default-sentinel":
+		return fmt.Sprintf("Overrides any prior value once set to anything other than the default (%q); the last config that sets it wins.", param)
+	default:
+		return ""
+	}
+}
+
+func (c *config) mergeConfigs(n config, source string) {
+	// Keep include processing first!
+	// Reason being to have original take precedent over the include.
+	// (Last config applied wins.)
+	if n.Include != "" {
+		includeFile := fmt.Sprintf("%sincludes\\%s.json", *configRoot, n.Include)
+		// Skip if we've read this file before.
+		if !includesSeen[includeFile] {
+			includesSeen[includeFile] = true
+			i := readFromFile(includeFile)
+
+			if i.GameFound {
+				slog.Debug("    Inlcuded " + n.Include + " configs...")
+				c.mergeConfigs(*i, includeFile)
+			}
+		} else {
+			slog.Debug("    Already seen " + n.Include + " in another config...")
+			logActivity(activity{Type: ActivityIncludeSkipped, Source: includeFile})
+		}
+	}
+
+	if n.VTuberSoftware != "" && !validVTuberSoftware[n.VTuberSoftware] {
+		slog.Debug("Invalid VTuberSoftware found: " + n.VTuberSoftware)
+		n.VTuberSoftware = ""
+	}
+
+	t := reflect.TypeOf(config{})
+	cv := reflect.ValueOf(c).Elem()
+	nv := reflect.ValueOf(n)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("merge")
+		if tag == "" {
+			continue
+		}
+
+		cf := cv.Field(i)
+		before := cf.Interface()
+		applyMergeStrategy(cf, nv.Field(i), tag)
+
+		if *explainMerge && !reflect.DeepEqual(before, cf.Interface()) {
+			jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+			fieldSources[jsonName] = source
+		}
+	}
+}
+
+func (c *config) applyOverrides() {
+	// Values that don't need to be passed into StreamerBot.
+	c.Include = ""
+
+	// Sanity check the VTuber Software set.
+	if !validVTuberSoftware[c.VTuberSoftware] {
+		slog.Debug("Invalid VTuberSoftware set. Using default: " + defaultVTuberSoftware + ".")
+		c.VTuberSoftware = defaultVTuberSoftware
+	}
+
+	// Apply overrides based on VTuberSoftware.
+	switch c.VTuberSoftware {
+	// PNGTuber Settings
+	case "Veadotube":
+		c.StreamTags = removeDuplicateStr(
+			append([]string{"VTuber", "RedPanda", "ENVTuber"}, c.StreamTags...),
+		)
+
+		// Disable VNyan Stuff.
+		c.OutfitPoll = false
+		c.VNyanOutfit = ""
+
+		// Disable incompatible redeems.
+		c.NoBeanie = false
+
+	// VTube Studio Settings
+	case "VTS":
+		c.StreamTags = removeDuplicateStr(
+			append([]string{"VTuber", "RedPanda", "Furry", "ENVTuber"}, c.StreamTags...),
+		)
+
+		// Disable VNyan Stuff
+		c.OutfitPoll = false
+		c.VNyanOutfit = ""
+
+		// Disable incompatible redeems.
+		c.NoBeanie = false
+
+	// VNyan Settings
+	case "VNyan":
+		c.StreamTags = removeDuplicateStr(
+			append([]string{"VTuber", "RedPanda", "Furry", "ENVTuber"}, c.StreamTags...),
+		)
+
+		// Outfit overrides.
+		if c.VNyanOutfit != "" {
+			c.OutfitPoll = false
+		}
+
+		// Disable incompatible redeems.
+		c.NoBeanie = false
+
+	// Facecam Settings
+	case "None":
+		// Disable incompatible redeems.
+	}
+
+	// Twitch supports max 10 tags.
+	tagCount := len(c.StreamTags)
+	slog.Debug("Found " + strconv.Itoa(tagCount) + " tags...")
+	if tagCount > 10 {
+		slog.Debug("More than 10 tags found. Please clean some of them up!")
+		c.StreamTags = c.StreamTags[:10]
+	}
+
+	// Set GameName to passed in value.
+	c.GameName = *game
+
+	// Oncall overrides.
+	if *onCall || c.OnCall {
+		c.OnCall = true
+		c.BedTime = false
+		c.CreepyTime = false
+		c.RaidRoulette = false
+	}
+}
+
+func sanitizeGame(s string) string {
+	for _, c := range []string{
+		":", "&", "#", "\\", "/", "?", "@", "+", "|", "=", ",",
+	} {
+		s = strings.Replace(s, c, "_", -1)
+	}
+	return s
+}
+
+func writeSchemaFile() {
+	f := *schemaFile
+	config := newConfig()
+
+	stringArrays := []string{
+		"streamtags",
+		"collaborators",
+	}
+
+	// Handle properties separately.
+	properties := make(map[string]any)
+	// Special cases or properties outside of struct.
+	properties["_comment"] = map[string]any{
+		"type": "string",
+	}
+	properties["$schema"] = map[string]any{
+		"type": "string",
+	}
+	for _, v := range stringArrays {
+		properties[v] = map[string]any{
+			"type": "array",
+			"items": []map[string]any{
+				{"type": "string"},
+			},
+		}
+	}
+
+	r := reflect.ValueOf(config)
+
+	// Derefrence the pointer.
+	if r.Kind() == reflect.Ptr {
+		r = r.Elem()
+	}
+
+	rules := map[string]fieldRule{}
+	for _, rule := range configFieldRules() {
+		rules[rule.jsonName] = rule
+	}
+
+	for i := 0; i < r.NumField(); i++ {
+		n := strings.ToLower(r.Type().Field(i).Name)
+		// streamtags are handled specially.
+		if slices.Contains(stringArrays, n) {
+			continue
+		}
+
+		t := r.Type().Field(i).Type.String()
+
+		// Convert type string to valid JSON schema values.
+		switch t {
+		case "int", "*int":
+			t = "integer"
+		case "bool":
+			t = "boolean"
+		}
+
+		prop := map[string]any{
+			"type": t,
+		}
+
+		if mergeTag := r.Type().Field(i).Tag.Get("merge"); mergeTag != "" {
+			if desc := mergeStrategyDescription(mergeTag); desc != "" {
+				prop["description"] = desc
+			}
+		}
+
+		if rule, ok := rules[n]; ok {
+			if rule.enum != nil {
+				enum := make([]any, len(rule.enum))
+				for j, v := range rule.enum {
+					enum[j] = v
+				}
+				prop["enum"] = enum
+			}
+			if rule.min != nil {
+				prop["minimum"] = *rule.min
+			}
+			if rule.max != nil {
+				prop["maximum"] = *rule.max
+			}
+			if rule.maxItems != nil {
+				prop["maxItems"] = *rule.maxItems
+			}
+		}
+
+		properties[n] = prop
+	}
+
+	// streamtags carries its own maxItems constraint even though its array
+	// type is built up above.
+	if rule, ok := rules["streamtags"]; ok && rule.maxItems != nil {
+		if prop, ok := properties["streamtags"].(map[string]any); ok {
+			prop["maxItems"] = *rule.maxItems
+		}
+	}
+
+	schema := make(map[string]any)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = "https://github.com/RedThePanda6/botconfig/schema.json"
+	schema["title"] = "botconfig"
+	schema["description"] = "Merged StreamerBot config produced by botconfig."
+	schema["type"] = "object"
+	schema["additionalProperties"] = false
+	schema["properties"] = properties
+
+	outputFile, err := os.Create(f)
+	if err != nil {
+		slog.Debug("Error creating schema file:", err.Error(), err)
+	}
+	defer outputFile.Close()
+
+	encoder := json.NewEncoder(outputFile)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(schema)
+	if err != nil {
+		slog.Debug("Error writing config file:", err.Error(), err)
+	}
+}
+
+// buildMergedConfig runs the full global/game/day/date/month layering and
+// merge pipeline for a single game, as of now. It returns the merged config,
+// the layer and rules.json files that were found and merged (in layering
+// order; include files pulled in by those layers are not listed), and the
+// resolved weekday and date. The file list is what the audit log hashes and
+// what /debug reports as provenance.
+func buildMergedConfig(game string, now time.Time) (*config, []string, string, string) {
+	weekday := now.Weekday().String()
+	// Override day of week for testing.
+	if len(*dayOverride) > 0 {
+		weekday = *dayOverride
+	}
+
+	// Grab base date items.
+	day := strconv.Itoa(now.Day())
+	month := fmt.Sprintf(now.Month().String())
+	year := strconv.Itoa(now.Year())
+
+	// Build cobination date items.
+	date := fmt.Sprintf(month + "-" + day)
+	if len(*dateOverride) > 0 {
+		date = *dateOverride
+	}
+	dateYear := fmt.Sprintf(date + "-" + year)
+	monthYear := fmt.Sprintf(month + "-" + year)
+
+	// Print everything for debugging.
+	slog.Debug("Today is " + weekday + "...")
+	slog.Debug("Date is " + date + "...")
+	slog.Debug("Date w/Year is " + dateYear + "...")
+	slog.Debug("Month is " + month + "...")
+	slog.Debug("Month w/Year is " + monthYear + "...")
+
+	saneGame := sanitizeGame(game)
+
+	// Set the names of the JSON files to merge.
+	globalFile := fmt.Sprintf("%sglobal.json", *configRoot)
+	gameFile := fmt.Sprintf("%sgames\\%s.json", *configRoot, saneGame)
+	dayFile := fmt.Sprintf("%sday\\%s.json", *configRoot, weekday)
+	dateFile := fmt.Sprintf("%sdate\\%s.json", *configRoot, date)
+	dateYearFile := fmt.Sprintf("%sdate\\%s.json", *configRoot, date)
+	monthFile := fmt.Sprintf("%smonth\\%s.json", *configRoot, month)
+	monthYearFile := fmt.Sprintf("%smonth\\%s.json", *configRoot, monthYear)
+
+	// Read the JSON files into data structures.
+	slog.Debug("Reading configs...")
+	globalConfig := readFromFile(globalFile)
+	gameConfig := readFromFile(gameFile)
+	dayConfig := readFromFile(dayFile)
+	dateConfig := readFromFile(dateFile)
+	dateYearConfig := readFromFile(dateYearFile)
+	monthConfig := readFromFile(monthFile)
+	monthYearConfig := readFromFile(monthYearFile)
+
+	// Combine the JSON files with preference for gameConfig.
+	// Included/Nested configs will be recursed during each merge.
+	slog.Debug("Merging configs...")
+	twitchConfigs := newConfig()
+	// Set VTuberSoftware based on flag.
+	twitchConfigs.VTuberSoftware = *vtuberSoftware
+
+	sources := []string{}
+
+	// global
+	if globalConfig.GameFound {
+		slog.Debug("  Global configs...")
+		twitchConfigs.mergeConfigs(*globalConfig, globalFile)
+		sources = append(sources, globalFile)
+	}
+
+	// game
+	if gameConfig.GameFound {
+		slog.Debug("  Game configs...")
+		twitchConfigs.mergeConfigs(*gameConfig, gameFile)
+		sources = append(sources, gameFile)
+	} else {
+		// If we don't find the game file then add the defaultTags.
+		twitchConfigs.StreamTags = removeDuplicateStr(
+			append(twitchConfigs.StreamTags, defaultTags...),
+		)
+	}
+
+	// day
+	if dayConfig.GameFound {
+		slog.Debug("  Day configs...")
+		twitchConfigs.mergeConfigs(*dayConfig, dayFile)
+		sources = append(sources, dayFile)
+	}
+
+	// date
+	if dateConfig.GameFound {
+		slog.Debug("  Date configs...")
+		twitchConfigs.mergeConfigs(*dateConfig, dateFile)
+		sources = append(sources, dateFile)
+	}
+
+	// date w/ year
+	if dateYearConfig.GameFound {
+		slog.Debug("  Date w/Year configs...")
+		twitchConfigs.mergeConfigs(*dateYearConfig, dateYearFile)
+		sources = append(sources, dateYearFile)
+	}
+
+	// month
+	if monthConfig.GameFound {
+		slog.Debug("  Month configs...")
+		twitchConfigs.mergeConfigs(*monthConfig, monthFile)
+		sources = append(sources, monthFile)
+	}
+
+	// month w/ year
+	if monthYearConfig.GameFound {
+		slog.Debug("  Month w/Year configs...")
+		twitchConfigs.mergeConfigs(*monthYearConfig, monthYearFile)
+		sources = append(sources, monthYearFile)
+	}
+
+	// rules.json: user-declared conditional overlays, layered in after the
+	// static files and sorted by ascending priority.
+	ruleCtx := ruleContext{
+		now:           now,
+		game:          game,
+		collaborators: twitchConfigs.Collaborators,
+		onCall:        *onCall || twitchConfigs.OnCall,
+	}
+	for _, ruleFile := range matchingRuleFiles(ruleCtx) {
+		ruleConfig := readFromFile(ruleFile)
+		if ruleConfig.GameFound {
+			slog.Debug("  Rule-matched configs: " + ruleFile)
+			twitchConfigs.mergeConfigs(*ruleConfig, ruleFile)
+			sources = append(sources, ruleFile)
+			logActivity(activity{Type: ActivityRuleMatched, Game: game, Source: ruleFile})
+		}
+	}
+
+	// Apply overrides.
+	twitchConfigs.applyOverrides()
+
+	// Things we need to set after all is said and done.
+	// Typically things we can't do in the applyOverrides scope.
+	twitchConfigs.SanitizedGameName = saneGame
+	twitchConfigs.GameFound = gameConfig.GameFound
+
+	// Report which file contributed each field, if requested.
+	if *explainMerge {
+		slog.Info("--explainMerge field provenance:")
+		for field, source := range fieldSources {
+			slog.Info("  " + field + " <- " + source)
+		}
+	}
+
+	return twitchConfigs, sources, weekday, date
+}
+
+func main() {
+	flag.Parse()
+
+	if *debug {
+		handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+		slog.SetDefault(slog.New(handler))
+	}
+
+	if !validVTuberSoftware[defaultVTuberSoftware] {
+		slog.Error("defaultVTuberSoftware is not a valid value. Fix it and recompile!")
+		os.Exit(1)
+	}
+
+	if *auditQuery != "" {
+		if !runAuditQuery(*auditQuery) {
+			slog.Error("Unrecognized --auditQuery: " + *auditQuery)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *kvImport != "" || *kvExport != "" {
+		runKVTool()
+		return
+	}
+
+	if err := selectConfigSource(); err != nil {
+		slog.Error("Error setting up --source=" + *source + ": " + err.Error())
+		os.Exit(1)
+	}
+
+	if *serve {
+		runServer()
+		return
+	}
+
+	if *game == "" {
+		slog.Error("--game flag required.")
+		os.Exit(1)
+	}
+
+	slog.Debug("Processing game " + *game + ".")
+
+	evalTime := time.Now()
+	if *nowOverride != "" {
+		parsed, err := time.Parse(time.RFC3339, *nowOverride)
+		if err != nil {
+			slog.Error("Invalid --now value: " + err.Error())
+			os.Exit(1)
+		}
+		evalTime = parsed
+	}
+
+	twitchConfigs, sources, weekday, date := buildMergedConfig(*game, evalTime)
+	recordEmit(*game, weekday, date, sources, twitchConfigs)
+
+	// Write to output file.
+	if *writeJSONFile {
+		slog.Debug("Writing JSON file...")
+		twitchConfigs.writeToFile(*outFile)
+	}
+
+	// Write out JSON.
+	// Disable SetEscapeHTML to hopefully avoid mangling characters in titlesuffix.
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(twitchConfigs); err != nil {
+		panic(err)
+	}
+
+	// Write out JSON schema.
+	if *writeSchema {
+		slog.Debug("Writing schema file...")
+		writeSchemaFile()
+	}
+
+	slog.Debug("End of Line.")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// applyStrategy runs applyMergeStrategy against freshly boxed cur/in values
+// and returns the resulting cf, so each case below can assert in plain Go
+// values instead of juggling reflect.Value.
+func applyStrategy(cur, in any, tag string) any {
+	cf := reflect.New(reflect.TypeOf(cur)).Elem()
+	cf.Set(reflect.ValueOf(cur))
+	applyMergeStrategy(cf, reflect.ValueOf(in), tag)
+	return cf.Interface()
+}
+
+func TestApplyMergeStrategy(t *testing.T) {
+	one, two := 1, 2
+
+	cases := []struct {
+		name string
+		tag  string
+		cur  any
+		in   any
+		want any
+	}{
+		{"override string takes incoming when set", "override", "a", "b", "b"},
+		{"override string keeps current when incoming empty", "override", "a", "", "a"},
+		{"override int takes incoming when nonzero", "override", 1, 2, 2},
+		{"override int keeps current when incoming zero", "override", 1, 0, 1},
+		{"override pointer takes incoming when set", "override", &one, &two, &two},
+		{"override pointer keeps current when incoming nil", "override", &one, (*int)(nil), &one},
+		{"keep-first never changes", "keep-first", "a", "b", "a"},
+		{"min keeps the smaller of the two", "min", 5, 3, 3},
+		{"min keeps current when already smaller", "min", 3, 5, 3},
+		{"max keeps the larger of the two", "max", 3, 5, 5},
+		{"max keeps current when already larger", "max", 5, 3, 5},
+		{"and is true only if both are true", "and", true, false, false},
+		{"and stays true when both are true", "and", true, true, true},
+		{"or is true if either is true", "or", false, true, true},
+		{"or stays false when both are false", "or", false, false, false},
+		{"append-unique merges without duplicates", "append-unique", []string{"a", "b"}, []string{"b", "c"}, []string{"a", "b", "c"}},
+		{"concat-sep joins current and incoming with the separator", "concat-sep= | ", "a", "b", "a | b"},
+		{"concat-sep keeps current when incoming is empty", "concat-sep= | ", "a", "", "a"},
+		{"concat-sep takes incoming when current is empty", "concat-sep= | ", "", "b", "b"},
+		{"default-sentinel string takes incoming when not the sentinel", "default-sentinel=default", "default", "custom", "custom"},
+		{"default-sentinel string keeps current when incoming is the sentinel", "default-sentinel=default", "custom", "default", "custom"},
+		{"default-sentinel int takes incoming when not the sentinel", "default-sentinel=250", 250, 500, 500},
+		{"default-sentinel int keeps current when incoming is the sentinel", "default-sentinel=250", 500, 250, 500},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := applyStrategy(tc.cur, tc.in, tc.tag)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("applyMergeStrategy(%v, %v, %q) = %#v, want %#v", tc.cur, tc.in, tc.tag, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestMergeConfigsEndMinuteZeroOverride is the regression case the
+// default-sentinel=0 tag used to get wrong: a later config that explicitly
+// sets EndMinute to 0 must be able to override an earlier non-zero value.
+func TestMergeConfigsEndMinuteZeroOverride(t *testing.T) {
+	priorMinute := 45
+	c := newConfig()
+	c.EndMinute = &priorMinute
+
+	zero := 0
+	c.mergeConfigs(config{EndMinute: &zero}, "test")
+
+	if c.EndMinute == nil || *c.EndMinute != 0 {
+		t.Fatalf("EndMinute = %v, want a pointer to 0", c.EndMinute)
+	}
+}
+
+// TestMergeConfigsEndMinuteUnsetKeepsPrior checks the other half of the same
+// fix: a config that simply omits EndMinute (nil) must leave an earlier
+// value alone rather than clobbering it.
+func TestMergeConfigsEndMinuteUnsetKeepsPrior(t *testing.T) {
+	priorMinute := 45
+	c := newConfig()
+	c.EndMinute = &priorMinute
+
+	c.mergeConfigs(config{}, "test")
+
+	if c.EndMinute == nil || *c.EndMinute != 45 {
+		t.Fatalf("EndMinute = %v, want a pointer to 45", c.EndMinute)
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// ConfigSource abstracts where config files (and their includes) come from,
+// so configRoot doesn't have to be a literal directory on disk. readFromFile
+// reads through activeSource rather than os directly; writeToFile still
+// writes the merged *output* artifact straight to disk, since that's the
+// StreamerBot hand-off point rather than a config input.
+type ConfigSource interface {
+	// Read returns the bytes at name, whether it exists, and any error
+	// other than "does not exist".
+	Read(name string) ([]byte, bool, error)
+	// List returns the names of files under prefix.
+	List(prefix string) ([]string, error)
+	// Watch reports a best-effort stream of changed names. Implementations
+	// that can't watch natively may poll.
+	Watch(ctx context.Context) <-chan Event
+}
+
+// Event is a single change reported by ConfigSource.Watch.
+type Event struct {
+	Name string
+	Op   string // "created", "modified", "removed"
+}
+
+// activeSource is the ConfigSource readFromFile resolves paths through.
+// Defaults to the filesystem, matching today's behavior; --source switches
+// it at startup.
+var activeSource ConfigSource = newFileConfigSource()
+
+// fileConfigSource is today's plain filesystem backend.
+type fileConfigSource struct {
+	mtimes map[string]time.Time
+	mu     sync.Mutex
+}
+
+func newFileConfigSource() *fileConfigSource {
+	return &fileConfigSource{mtimes: map[string]time.Time{}}
+}
+
+func (s *fileConfigSource) Read(name string) ([]byte, bool, error) {
+	data, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	return data, true, nil
+}
+
+func (s *fileConfigSource) List(prefix string) ([]string, error) {
+	names := []string{}
+	err := filepath.WalkDir(prefix, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".json") {
+			names = append(names, path)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	return names, err
+}
+
+// Watch polls every --pollIntervalSec and reports files whose mtime changed
+// since the last poll. Good enough for a Google Drive mount where native
+// notifications are unreliable anyway.
+func (s *fileConfigSource) Watch(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(time.Duration(*pollIntervalSec) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				names, err := s.List(*configRoot)
+				if err != nil {
+					continue
+				}
+				s.mu.Lock()
+				for _, name := range names {
+					info, err := os.Stat(name)
+					if err != nil {
+						continue
+					}
+					mtime := info.ModTime()
+					if prev, ok := s.mtimes[name]; !ok {
+						s.mtimes[name] = mtime
+						select {
+						case events <- Event{Name: name, Op: "created"}:
+						case <-ctx.Done():
+							s.mu.Unlock()
+							return
+						}
+					} else if !prev.Equal(mtime) {
+						s.mtimes[name] = mtime
+						select {
+						case events <- Event{Name: name, Op: "modified"}:
+						case <-ctx.Done():
+							s.mu.Unlock()
+							return
+						}
+					}
+				}
+				s.mu.Unlock()
+			}
+		}
+	}()
+
+	return events
+}
+
+// kvBucket is the single bbolt bucket kvConfigSource keeps every config key
+// in; one flat namespace mirrors how the filesystem backend keys things (a
+// relative path string), so Import/Export can translate between the two
+// without any extra structure.
+var kvBucket = []byte("configs")
+
+// kvConfigSource is an embedded key/value store behind the same
+// ConfigSource interface as fileConfigSource, so configs can live as
+// atomically written keys instead of loose files on a synced drive (the
+// motivating case: streamers on multiple machines fighting Google Drive's
+// file-locking quirks). It's backed by bbolt, a pure-Go embedded B+tree
+// store: bbolt.Open memory-maps the file and every Update runs in its own
+// ACID transaction, so Set gives the same "never a torn write" guarantee
+// the original JSON-blob stand-in only approximated with a temp-file
+// rename, and for free across concurrent readers too.
+type kvConfigSource struct {
+	db *bbolt.DB
+}
+
+func newKVConfigSource(path string) (*kvConfigSource, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(kvBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &kvConfigSource{db: db}, nil
+}
+
+func (s *kvConfigSource) Read(name string) ([]byte, bool, error) {
+	var value []byte
+	found := false
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(kvBucket).Get([]byte(name))
+		if v != nil {
+			found = true
+			value = append([]byte(nil), v...) // v is only valid for the transaction's lifetime
+		}
+		return nil
+	})
+
+	return value, found, err
+}
+
+func (s *kvConfigSource) List(prefix string) ([]string, error) {
+	names := []string{}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(kvBucket).ForEach(func(k, v []byte) error {
+			if strings.HasPrefix(string(k), prefix) {
+				names = append(names, string(k))
+			}
+			return nil
+		})
+	})
+
+	return names, err
+}
+
+// Watch is unsupported for now; the KV store is only ever updated through
+// Import, which the daemon isn't yet wired to re-poll.
+func (s *kvConfigSource) Watch(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	close(events)
+	return events
+}
+
+// Set writes a key in its own bbolt transaction. Used by Import.
+func (s *kvConfigSource) Set(name string, value []byte) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(kvBucket).Put([]byte(name), value)
+	})
+}
+
+// Close releases the bbolt file lock and memory mapping. Callers that open
+// a kvConfigSource outside of selectConfigSource's process lifetime (e.g.
+// runKVTool) should defer this.
+func (s *kvConfigSource) Close() error {
+	return s.db.Close()
+}
+
+// atomicWriteFile writes data to a temp file in the same directory as path
+// and renames it into place, so a reader never observes a partially written
+// file. Same-directory matters: os.Rename is only atomic within a single
+// filesystem, and a temp dir elsewhere (e.g. on a different mount than a
+// synced Google Drive folder) wouldn't give that guarantee.
+func atomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// Import walks a fileConfigSource rooted at dir and copies every *.json file
+// into the KV store, keyed by its path relative to dir. This backs
+// --kvImport.
+func (s *kvConfigSource) Import(dir string) (int, error) {
+	fileSrc := newFileConfigSource()
+	names, err := fileSrc.List(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, name := range names {
+		data, _, err := fileSrc.Read(name)
+		if err != nil {
+			return 0, err
+		}
+		key := strings.TrimPrefix(name, dir)
+		if err := s.Set(key, data); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(names), nil
+}
+
+// Export writes every key in the KV store back out as a *.json file under
+// dir. This backs --kvExport.
+func (s *kvConfigSource) Export(dir string) (int, error) {
+	count := 0
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(kvBucket).ForEach(func(k, v []byte) error {
+			target := filepath.Join(dir, string(k))
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := atomicWriteFile(target, v, 0o644); err != nil {
+				return err
+			}
+			count++
+			return nil
+		})
+	})
+
+	return count, err
+}
+
+// selectConfigSource sets activeSource based on --source. Called once from
+// main before any config is read.
+func selectConfigSource() error {
+	switch *source {
+	case "", "file":
+		activeSource = newFileConfigSource()
+	case "kv":
+		kv, err := newKVConfigSource(*kvFile)
+		if err != nil {
+			return err
+		}
+		activeSource = kv
+	case "git":
+		git, err := newGitConfigSource(*gitRepo, *gitLocalPath, time.Duration(*gitPullIntervalSec)*time.Second)
+		if err != nil {
+			return err
+		}
+		activeSource = git
+	}
+	return nil
+}
+
+// runKVTool backs --kvImport/--kvExport, a tiny CLI for moving config files
+// in and out of the kv ConfigSource without standing up the full merge
+// pipeline.
+func runKVTool() {
+	kv, err := newKVConfigSource(*kvFile)
+	if err != nil {
+		slog.Error("Error opening --kvFile: " + err.Error())
+		os.Exit(1)
+	}
+	defer kv.Close()
+
+	if *kvImport != "" {
+		n, err := kv.Import(*kvImport)
+		if err != nil {
+			slog.Error("Error importing into kv store: " + err.Error())
+			os.Exit(1)
+		}
+		slog.Info("Imported files into kv store", "count", n, "kvFile", *kvFile)
+	}
+
+	if *kvExport != "" {
+		n, err := kv.Export(*kvExport)
+		if err != nil {
+			slog.Error("Error exporting kv store: " + err.Error())
+			os.Exit(1)
+		}
+		slog.Info("Exported keys from kv store", "count", n, "dir", *kvExport)
+	}
+}
+
+// gitConfigSource pulls a git repo on an interval and serves configs out of
+// the resulting working tree, giving streamers history/rollback on their
+// config changes. It shells out to the system git binary rather than
+// vendoring a Go git implementation.
+type gitConfigSource struct {
+	repoURL      string
+	localPath    string
+	pullInterval time.Duration
+	inner        *fileConfigSource
+}
+
+func newGitConfigSource(repoURL, localPath string, pullInterval time.Duration) (*gitConfigSource, error) {
+	s := &gitConfigSource{
+		repoURL:      repoURL,
+		localPath:    localPath,
+		pullInterval: pullInterval,
+		inner:        newFileConfigSource(),
+	}
+
+	if _, err := os.Stat(filepath.Join(localPath, ".git")); os.IsNotExist(err) {
+		if err := exec.Command("git", "clone", repoURL, localPath).Run(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *gitConfigSource) pull() error {
+	cmd := exec.Command("git", "-C", s.localPath, "pull", "--ff-only")
+	return cmd.Run()
+}
+
+func (s *gitConfigSource) Read(name string) ([]byte, bool, error) {
+	return s.inner.Read(filepath.Join(s.localPath, name))
+}
+
+func (s *gitConfigSource) List(prefix string) ([]string, error) {
+	return s.inner.List(filepath.Join(s.localPath, prefix))
+}
+
+// Watch pulls on pullInterval and reports every file that differs from the
+// inner filesystem watcher's view afterwards.
+func (s *gitConfigSource) Watch(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(s.pullInterval)
+		defer ticker.Stop()
+
+		inner := s.inner.Watch(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.pull(); err != nil {
+					continue
+				}
+			case e, ok := <-inner:
+				if !ok {
+					return
+				}
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAtomicWriteFileReplacesContentAndLeavesNoTempBehind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+
+	if err := atomicWriteFile(path, []byte(`{"a":1}`), 0o644); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	if err := atomicWriteFile(path, []byte(`{"a":2}`), 0o644); err != nil {
+		t.Fatalf("second write: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading back: %v", err)
+	}
+	if string(got) != `{"a":2}` {
+		t.Fatalf("content = %q, want the second write's content", got)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("dir has %d entries, want exactly the final store.json with no leftover temp file", len(entries))
+	}
+}
+
+func TestKVConfigSourceSetPersistsAcrossReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+
+	s, err := newKVConfigSource(path)
+	if err != nil {
+		t.Fatalf("newKVConfigSource: %v", err)
+	}
+	if err := s.Set("game.json", []byte(`{"gamename":"Test"}`)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	// bbolt holds an exclusive file lock for the life of the handle, so the
+	// reload below has to wait for this one to close first.
+	if err := s.Close(); err != nil {
+		t.Fatalf("closing first handle: %v", err)
+	}
+
+	reloaded, err := newKVConfigSource(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	defer reloaded.Close()
+	data, ok, err := reloaded.Read("game.json")
+	if err != nil || !ok {
+		t.Fatalf("Read after reload: data=%q ok=%v err=%v", data, ok, err)
+	}
+	if string(data) != `{"gamename":"Test"}` {
+		t.Fatalf("data = %q, want the value written before reload", data)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+)
+
+// rules.json lets streamers declare conditional overlays without inventing
+// new directory conventions, e.g. applying spooky.json on on-call Fridays
+// after 8pm. Matching rules are sorted by priority (ascending, so higher
+// priority wins like every other "last config applied wins" layer) and
+// merged in after the static global/game/day/date/month files.
+
+// ruleCondition is the `when` clause of a rule. Predicates left as their
+// zero value are skipped; everything present must match (it's an implicit
+// allOf across the named predicates plus any explicit allOf/anyOf/not).
+type ruleCondition struct {
+	Weekday             []string        `json:"weekday,omitempty"`
+	Date                []string        `json:"date,omitempty"`
+	DateRange           []string        `json:"dateRange,omitempty"`
+	MonthRange          []string        `json:"monthRange,omitempty"`
+	HourRange           []int           `json:"hourRange,omitempty"`
+	Game                string          `json:"gameMatches,omitempty"`
+	CollaboratorPresent string          `json:"collaboratorPresent,omitempty"`
+	OnCall              *bool           `json:"onCall,omitempty"`
+	AllOf               []ruleCondition `json:"allOf,omitempty"`
+	AnyOf               []ruleCondition `json:"anyOf,omitempty"`
+	Not                 *ruleCondition  `json:"not,omitempty"`
+}
+
+// configRule is one entry in rules.json.
+type configRule struct {
+	When     ruleCondition `json:"when"`
+	Apply    string        `json:"apply"`
+	Priority int           `json:"priority"`
+}
+
+// ruleContext is the moment-in-time+game state predicates are matched
+// against. now drives every time-based predicate, so pinning it (via --now)
+// makes rule matching deterministic for testing.
+type ruleContext struct {
+	now           time.Time
+	game          string
+	collaborators []string
+	onCall        bool
+}
+
+// monthDay parses the "Month-Day" format buildMergedConfig already builds
+// date/dateYear file names from (e.g. "July-26"), ignoring year, for use in
+// dateRange/monthRange comparisons.
+func monthDay(s string) (time.Time, error) {
+	return time.Parse("January-2", s)
+}
+
+func (cond ruleCondition) matches(ctx ruleContext) bool {
+	for _, c := range cond.AllOf {
+		if !c.matches(ctx) {
+			return false
+		}
+	}
+
+	if len(cond.AnyOf) > 0 {
+		matched := false
+		for _, c := range cond.AnyOf {
+			if c.matches(ctx) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if cond.Not != nil && cond.Not.matches(ctx) {
+		return false
+	}
+
+	if len(cond.Weekday) > 0 && !slices.Contains(cond.Weekday, ctx.now.Weekday().String()) {
+		return false
+	}
+
+	if len(cond.Date) > 0 {
+		today := fmt.Sprintf("%s-%d", ctx.now.Month().String(), ctx.now.Day())
+		if !slices.Contains(cond.Date, today) {
+			return false
+		}
+	}
+
+	if len(cond.DateRange) == 2 {
+		from, err1 := monthDay(cond.DateRange[0])
+		to, err2 := monthDay(cond.DateRange[1])
+		today, err3 := monthDay(fmt.Sprintf("%s-%d", ctx.now.Month().String(), ctx.now.Day()))
+		if err1 != nil || err2 != nil || err3 != nil {
+			slog.Debug("Invalid dateRange in rules.json")
+			return false
+		}
+		if today.Before(from) || today.After(to) {
+			return false
+		}
+	}
+
+	if len(cond.MonthRange) == 2 {
+		from, err1 := time.Parse("January", cond.MonthRange[0])
+		to, err2 := time.Parse("January", cond.MonthRange[1])
+		if err1 != nil || err2 != nil {
+			slog.Debug("Invalid monthRange in rules.json")
+			return false
+		}
+		if ctx.now.Month() < from.Month() || ctx.now.Month() > to.Month() {
+			return false
+		}
+	}
+
+	if len(cond.HourRange) == 2 {
+		lo, hi := cond.HourRange[0], cond.HourRange[1]
+		hour := ctx.now.Hour()
+		if hi > 24 {
+			// Wraps past midnight, e.g. [20,26] == 8pm-2am.
+			if hour < lo && hour >= hi-24 {
+				return false
+			}
+		} else if hour < lo || hour >= hi {
+			return false
+		}
+	}
+
+	if cond.Game != "" {
+		matched, err := regexp.MatchString(cond.Game, ctx.game)
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	if cond.CollaboratorPresent != "" && !slices.Contains(ctx.collaborators, cond.CollaboratorPresent) {
+		return false
+	}
+
+	if cond.OnCall != nil && *cond.OnCall != ctx.onCall {
+		return false
+	}
+
+	return true
+}
+
+// loadRules reads rules.json through activeSource, so it honors --source
+// like every other config file.
+func loadRules() ([]configRule, error) {
+	data, found, err := activeSource.Read(*configRoot + "rules.json")
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var rules []configRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// matchingRuleFiles returns the "apply" files of every rule whose "when"
+// matches ctx, sorted by ascending priority so higher-priority rules are
+// merged in last and win ties, consistent with the rest of the pipeline.
+func matchingRuleFiles(ctx ruleContext) []string {
+	rules, err := loadRules()
+	if err != nil {
+		slog.Debug("Error loading rules.json: " + err.Error())
+		return nil
+	}
+
+	matched := []configRule{}
+	for _, r := range rules {
+		if r.When.matches(ctx) {
+			matched = append(matched, r)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Priority < matched[j].Priority
+	})
+
+	files := make([]string, len(matched))
+	for i, r := range matched {
+		files[i] = *configRoot + r.Apply
+	}
+	return files
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"google.golang.org/grpc"
+)
+
+// This file backs --serve, a long-running alternative to the normal
+// one-shot CLI invocation so StreamerBot doesn't have to re-exec the binary
+// on every scene switch. Both RPC surfaces from the original request are
+// served side by side: an HTTP/SSE mirror (/config, /watch, /debug) and a
+// gRPC ConfigService with Get/Watch (serve_grpc.go), listening on
+// --serveAddr and --grpcAddr respectively.
+//
+// Changes to configRoot are picked up two ways: an fsnotify watch on
+// configRoot (and its includes subdirectory) for immediate reaction, and
+// the pollAndPublish ticker below as a backstop — configRoot often lives on
+// a Google Drive mount, which is flaky about native filesystem
+// notifications, and the poll also naturally picks up day/date/month
+// boundary rollovers that don't touch any file.
+
+// subscriber is a single /watch connection's delivery channel. Modeled on
+// the AddFollower/RemoveFollower pattern from v2ray's FollowLog: a central
+// hub owns fan-out, each connection just drains its own channel and
+// unregisters on disconnect.
+type subscriber struct {
+	id int
+	ch chan *config
+}
+
+// configHub fans newly built configs out to every /watch subscriber for a
+// given game.
+type configHub struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[string][]*subscriber
+}
+
+func newConfigHub() *configHub {
+	return &configHub{subs: map[string][]*subscriber{}}
+}
+
+func (h *configHub) addSubscriber(game string) *subscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	s := &subscriber{id: h.nextID, ch: make(chan *config, 1)}
+	h.subs[game] = append(h.subs[game], s)
+	return s
+}
+
+func (h *configHub) removeSubscriber(game string, s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs := h.subs[game]
+	for i, sub := range subs {
+		if sub == s {
+			h.subs[game] = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+}
+
+// publish pushes c to every subscriber on game. Slow subscribers that
+// haven't drained their previous update are skipped rather than blocking
+// the rest of the hub.
+func (h *configHub) publish(game string, c *config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range h.subs[game] {
+		select {
+		case sub.ch <- c:
+		default:
+			slog.Debug("Dropping /watch update for slow subscriber on " + game)
+		}
+	}
+}
+
+// activeGames returns the games with at least one live /watch subscriber,
+// so the poller only rebuilds configs anyone actually cares about.
+func (h *configHub) activeGames() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	games := make([]string, 0, len(h.subs))
+	for game, subs := range h.subs {
+		if len(subs) > 0 {
+			games = append(games, game)
+		}
+	}
+	return games
+}
+
+// debugSnapshot is what /debug reports for the most recently built config
+// of each game, including which files contributed to it.
+type debugSnapshot struct {
+	Config  *config   `json:"config"`
+	Sources []string  `json:"sources"`
+	BuiltAt time.Time `json:"builtAt"`
+}
+
+type configServer struct {
+	hub *configHub
+
+	mu        sync.Mutex
+	lastBuilt map[string]debugSnapshot
+}
+
+func newConfigServer() *configServer {
+	return &configServer{
+		hub:       newConfigHub(),
+		lastBuilt: map[string]debugSnapshot{},
+	}
+}
+
+func (s *configServer) build(game string) debugSnapshot {
+	c, sources, weekday, date := buildMergedConfig(game, time.Now())
+	recordEmit(game, weekday, date, sources, c)
+	snap := debugSnapshot{Config: c, Sources: sources, BuiltAt: time.Now()}
+
+	s.mu.Lock()
+	s.lastBuilt[game] = snap
+	s.mu.Unlock()
+
+	return snap
+}
+
+// handleConfig mirrors the gRPC Get(GameRequest) call: build once and return.
+func (s *configServer) handleConfig(w http.ResponseWriter, r *http.Request) {
+	game := r.URL.Query().Get("game")
+	if game == "" {
+		http.Error(w, "game query parameter required", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.build(game).Config)
+}
+
+func (s *configServer) handleDebug(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.lastBuilt)
+}
+
+// handleWatch mirrors the gRPC Watch(GameRequest) stream as Server-Sent
+// Events: the current config immediately, then one "data:" frame per
+// change, with a heartbeat frame every --heartbeatSec so clients can
+// detect a broken pipe.
+func (s *configServer) handleWatch(w http.ResponseWriter, r *http.Request) {
+	game := r.URL.Query().Get("game")
+	if game == "" {
+		http.Error(w, "game query parameter required", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	sub := s.hub.addSubscriber(game)
+	defer s.hub.removeSubscriber(game, sub)
+
+	writeSSEConfig(w, s.build(game).Config)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(time.Duration(*heartbeatSec) * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case c := <-sub.ch:
+			writeSSEConfig(w, c)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSEConfig(w http.ResponseWriter, c *config) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		slog.Debug("Error marshaling config for /watch: " + err.Error())
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
+
+// pollAndPublish re-evaluates every game with active /watch subscribers and
+// pushes a fresh config to the hub. It's the backstop for the fsnotify
+// watch below: it also naturally picks up day/date/month boundary
+// rollovers on the next tick without any separate clock-watching logic.
+func (s *configServer) pollAndPublish() {
+	for _, game := range s.hub.activeGames() {
+		s.hub.publish(game, s.build(game).Config)
+	}
+}
+
+// watchConfigRoot watches configRoot (and its includes subdirectory, if
+// present) with fsnotify and re-publishes every active game as soon as
+// anything changes, instead of waiting for the next poll tick. It runs
+// forever; fsnotify setup failures (e.g. configRoot doesn't exist yet) are
+// logged and left to the poll loop to cover.
+//
+// Events under auditDir() are ignored: the audit log lives inside
+// configRoot (see audit.go), so every emit would otherwise retrigger this
+// same watch and rebuild forever.
+func watchConfigRoot(s *configServer) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Warn("fsnotify unavailable, relying on --pollIntervalSec only: " + err.Error())
+		return
+	}
+	defer watcher.Close()
+
+	for _, dir := range []string{*configRoot, *configRoot + "includes"} {
+		if err := watcher.Add(dir); err != nil {
+			slog.Debug("Not watching " + dir + ": " + err.Error())
+		}
+	}
+
+	auditPrefix := auditDir()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if strings.HasPrefix(event.Name, auditPrefix) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			slog.Debug("configRoot change detected: " + event.String())
+			s.pollAndPublish()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("fsnotify error: " + err.Error())
+		}
+	}
+}
+
+func runServer() {
+	srv := newConfigServer()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/config", srv.handleConfig)
+	mux.HandleFunc("/watch", srv.handleWatch)
+	mux.HandleFunc("/debug", srv.handleDebug)
+
+	go watchConfigRoot(srv)
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(*pollIntervalSec) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			srv.pollAndPublish()
+		}
+	}()
+
+	go func() {
+		grpcServer := grpc.NewServer()
+		grpcServer.RegisterService(&configServiceDesc, srv)
+
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			slog.Error("Error listening for gRPC: " + err.Error())
+			os.Exit(1)
+		}
+		slog.Info("Serving gRPC ConfigService on " + *grpcAddr)
+		if err := grpcServer.Serve(listener); err != nil {
+			slog.Error("gRPC server error: " + err.Error())
+		}
+	}()
+
+	if *serveSocket != "" {
+		os.Remove(*serveSocket)
+		listener, err := net.Listen("unix", *serveSocket)
+		if err != nil {
+			slog.Error("Error listening on socket: " + err.Error())
+			os.Exit(1)
+		}
+		slog.Info("Serving on unix socket " + *serveSocket)
+		if err := http.Serve(listener, mux); err != nil {
+			slog.Error("HTTP server error: " + err.Error())
+		}
+		return
+	}
+
+	slog.Info("Serving on " + *serveAddr)
+	if err := http.ListenAndServe(*serveAddr, mux); err != nil {
+		slog.Error("HTTP server error: " + err.Error())
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// serve_grpc.go gives configServer a real gRPC ConfigService: Get(game)
+// returns one merged config, Watch(game) streams one every time the hub
+// publishes an update for it, same data as the /config and /watch HTTP
+// handlers in serve.go.
+//
+// There's no .proto file here and nothing was run through protoc: this
+// sandbox doesn't have a protoc/protoc-gen-go-grpc toolchain installed, so
+// the grpc.ServiceDesc that protoc-gen-go-grpc would normally generate is
+// written out by hand below, against the well-known message types
+// (wrapperspb.StringValue, structpb.Struct) that ship pre-built with
+// google.golang.org/protobuf instead of a custom-generated GameRequest /
+// ConfigResponse pair. The wire format is still real protobuf and the RPCs
+// are genuinely unary/server-streaming gRPC, just with generic message
+// shapes in place of custom ones: a request is its game name as a string
+// value, a response is the merged config marshaled to JSON and decoded
+// into a google.protobuf.Struct.
+
+const configServiceName = "streamerbot.ConfigService"
+
+// configWatchStream is the subset of grpc.ServerStream the hand-written
+// Watch handler below needs, narrowed to the one message type Watch sends.
+type configWatchStream interface {
+	grpc.ServerStream
+	Send(*structpb.Struct) error
+}
+
+type configServerStream struct {
+	grpc.ServerStream
+}
+
+func (x *configServerStream) Send(m *structpb.Struct) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// configToStruct converts a merged config to a google.protobuf.Struct by
+// round-tripping it through JSON, the same encoding /config and /watch
+// already use, so all three surfaces describe identical shapes.
+func configToStruct(c *config) (*structpb.Struct, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "marshaling config: %v", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, status.Errorf(codes.Internal, "decoding config for gRPC: %v", err)
+	}
+
+	s, err := structpb.NewStruct(raw)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "building protobuf struct: %v", err)
+	}
+	return s, nil
+}
+
+// Get backs the gRPC ConfigService.Get(GameRequest) RPC: build game's
+// merged config once and return it, same as handleConfig.
+func (s *configServer) Get(ctx context.Context, req *wrapperspb.StringValue) (*structpb.Struct, error) {
+	game := req.GetValue()
+	if game == "" {
+		return nil, status.Error(codes.InvalidArgument, "game is required")
+	}
+	return configToStruct(s.build(game).Config)
+}
+
+// Watch backs the gRPC ConfigService.Watch(GameRequest) streaming RPC:
+// send game's current config immediately, then one frame per hub update,
+// with a heartbeat (an empty Struct) every --heartbeatSec so clients can
+// detect a broken pipe, same as handleWatch's SSE stream.
+func (s *configServer) Watch(req *wrapperspb.StringValue, stream configWatchStream) error {
+	game := req.GetValue()
+	if game == "" {
+		return status.Error(codes.InvalidArgument, "game is required")
+	}
+
+	sub := s.hub.addSubscriber(game)
+	defer s.hub.removeSubscriber(game, sub)
+
+	first, err := configToStruct(s.build(game).Config)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(first); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(time.Duration(*heartbeatSec) * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case c := <-sub.ch:
+			msg, err := configToStruct(c)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if err := stream.Send(&structpb.Struct{}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func configGetHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(wrapperspb.StringValue)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*configServer).Get(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: configServiceName + "/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(*configServer).Get(ctx, req.(*wrapperspb.StringValue))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+func configWatchHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(wrapperspb.StringValue)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(*configServer).Watch(req, &configServerStream{stream})
+}
+
+// configServiceDesc is the grpc.ServiceDesc protoc-gen-go-grpc would have
+// generated from a ConfigService .proto; see the file comment for why it's
+// hand-written instead.
+var configServiceDesc = grpc.ServiceDesc{
+	ServiceName: configServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Get", Handler: configGetHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Watch", Handler: configWatchHandler, ServerStreams: true},
+	},
+	Metadata: "configservice.proto",
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestConfigToStruct(t *testing.T) {
+	c := &config{GameName: "testgame", BambooRequestCost: 55}
+
+	s, err := configToStruct(c)
+	if err != nil {
+		t.Fatalf("configToStruct: %v", err)
+	}
+
+	got := s.AsMap()
+	if got["gamename"] != "testgame" {
+		t.Errorf("gamename = %v, want %q", got["gamename"], "testgame")
+	}
+	if got["bamboorequestcost"] != float64(55) {
+		t.Errorf("bamboorequestcost = %v, want %v", got["bamboorequestcost"], float64(55))
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// cli.go restructures the binary from a single flag-driven entrypoint into
+// a small subcommand dispatcher, so it's usable both as the bot-pipeline
+// merger (`merge`, the historical default behavior) and as an operator
+// debugging CLI (`schema`, `validate`, `diff`, `convert`).
+
+// command is one subcommand: its own flag set (sharing the package-level
+// flag vars where it makes sense, e.g. --modelFile/--configRoot) plus a
+// run function taking the flag set's positional args.
+type command struct {
+	name  string
+	short string
+	flags *flag.FlagSet
+	run   func(args []string)
+}
+
+// newCommonFlagSet builds a FlagSet for name that registers every
+// package-level flag onto it (bound to the same variables flag.String
+// etc. already created), so any subcommand can read --modelFile,
+// --configRoot, and so on without redeclaring them.
+func newCommonFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.BoolVar(debug, "debug", *debug, "Print debugging info.")
+	fs.StringVar(modelFile, "modelFile", *modelFile, "Model file of config we're loading.")
+	fs.StringVar(configRoot, "configRoot", *configRoot, "Root folder where configs are found.")
+	fs.BoolVar(writeJSONFile, "writeJSONFile", *writeJSONFile, "Write a JSON file?")
+	fs.StringVar(outFile, "outFile", *outFile, "The output file we write merged configs to.")
+	fs.BoolVar(writeSchema, "writeSchema", *writeSchema, "Write a schema file?")
+	fs.StringVar(schemaFile, "schemaFile", *schemaFile, "The schema file used to validate configs.")
+	fs.BoolVar(strictSchema, "strictSchema", *strictSchema, "Abort with a non-zero exit code listing every schema violation instead of just logging a warning.")
+	fs.BoolVar(watch, "watch", *watch, "Keep running after the initial merge, re-merging and rewriting outFile whenever a contributing file changes.")
+	fs.StringVar(watchAddr, "watchAddr", *watchAddr, "If set, serve /config and /reload over HTTP at this address while --watch is running.")
+	return fs
+}
+
+var convertFormat string
+
+func commands() []command {
+	merge := newCommonFlagSet("merge")
+
+	schemaCmd := newCommonFlagSet("schema")
+
+	validateCmd := newCommonFlagSet("validate")
+
+	diffCmd := newCommonFlagSet("diff")
+
+	convertCmd := newCommonFlagSet("convert")
+	convertCmd.StringVar(&convertFormat, "format", "json", "Input format to convert from: json, yaml, or toml (yaml/toml support a scalars-and-nested-mappings subset only, see convert_formats.go).")
+
+	return []command{
+		{
+			name:  "merge",
+			short: "Resolve --modelFile plus its includes and write the merged config (the original default behavior).",
+			flags: merge,
+			run:   func(args []string) { runMergeCommand() },
+		},
+		{
+			name:  "schema",
+			short: "Write the JSON Schema for config to --schemaFile.",
+			flags: schemaCmd,
+			run:   func(args []string) { writeSchemaFile() },
+		},
+		{
+			name:  "validate",
+			short: "Validate one or more config files against the schema without merging them.",
+			flags: validateCmd,
+			run:   runValidateCommand,
+		},
+		{
+			name:  "diff",
+			short: "Show which redeems differ between two config files after full include resolution.",
+			flags: diffCmd,
+			run:   runDiffCommand,
+		},
+		{
+			name:  "convert",
+			short: "Read a config in --format and write canonical JSON for StreamerBot.",
+			flags: convertCmd,
+			run:   runConvertCommand,
+		},
+	}
+}
+
+// runValidateCommand backs `validate <file...>`: schema-check every file
+// given without attempting to merge includes, printing violations (if
+// any) per file and exiting non-zero if any file had one.
+func runValidateCommand(args []string) {
+	if len(args) == 0 {
+		slog.Error("Usage: validate <file...>")
+		os.Exit(1)
+	}
+
+	s := buildSchema()
+	failed := false
+
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Error(path + ": " + err.Error())
+			failed = true
+			continue
+		}
+
+		var raw map[string]any
+		if err := json.Unmarshal(data, &raw); err != nil {
+			slog.Error(path + ": invalid JSON: " + err.Error())
+			failed = true
+			continue
+		}
+
+		violations := validateAgainstSchema(s, raw)
+		if len(violations) == 0 {
+			fmt.Printf("%s: OK\n", path)
+			continue
+		}
+
+		failed = true
+		fmt.Printf("%s:\n", path)
+		for _, v := range violations {
+			fmt.Printf("  %s\n", v)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runDiffCommand backs `diff <a.json> <b.json>`: fully resolve each file
+// (includes and all) the same way `merge` would, then report which
+// redeems differ.
+func runDiffCommand(args []string) {
+	if len(args) != 2 {
+		slog.Error("Usage: diff <a.json> <b.json>")
+		os.Exit(1)
+	}
+
+	resolve := func(path string) config {
+		includesSeen = map[string]bool{}
+		c := readFromFile(path)
+		merged, _ := mergeConfigs(newConfig(), c)
+		merged.ConfigFound = c.ConfigFound
+		return applyOverrides(merged)
+	}
+
+	a := resolve(args[0])
+	b := resolve(args[1])
+
+	diffs := diffBools(a, b)
+	if len(diffs) == 0 {
+		fmt.Println("No redeem differences.")
+		return
+	}
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+}
+
+// runConvertCommand backs `convert --format=...`: read --modelFile in the
+// given format and write canonical JSON to --outFile/stdout, same as
+// writeMergedModelConfig. yaml and toml go through the hand-rolled,
+// config-shape-specific parsers in convert_formats.go rather than a full
+// vendored library, since all either format needs to express here is
+// scalar key/values plus nested mappings. Only some unsupported syntax
+// fails with an error (see convert_formats.go); the rest, such as lists or
+// inline tables, is read as a plain string value.
+func runConvertCommand(args []string) {
+	if *modelFile == "" {
+		slog.Error("--modelFile flag required.")
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*modelFile)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	switch convertFormat {
+	case "json":
+		// Already JSON; decodeConfigBytes below takes it as-is.
+	case "yaml":
+		raw, err := parseYAMLMapping(data)
+		if err != nil {
+			slog.Error("parsing --format=yaml: " + err.Error())
+			os.Exit(1)
+		}
+		if data, err = json.Marshal(raw); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	case "toml":
+		raw, err := parseTOMLMapping(data)
+		if err != nil {
+			slog.Error("parsing --format=toml: " + err.Error())
+			os.Exit(1)
+		}
+		if data, err = json.Marshal(raw); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	default:
+		slog.Error(fmt.Sprintf("--format=%s is not supported; use json, yaml, or toml", convertFormat))
+		os.Exit(1)
+	}
+
+	c := decodeConfigBytes(data, *modelFile)
+	writeMergedModelConfig(c)
+}
+
+// printUsage lists every subcommand, for the no-args/unknown-subcommand case.
+func printUsage(cmds []command) {
+	fmt.Println("Usage: modelconfig <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, c := range cmds {
+		fmt.Printf("  %-10s %s\n", c.name, c.short)
+	}
+}
+
+// dispatchCommand figures out which subcommand was requested and runs it.
+// With no arguments, or a first argument that's a flag (the original
+// single-flag-driven calling convention, e.g. "modelconfig -modelFile=..."
+// with no subcommand), it defaults to `merge`. Any other first argument
+// must name a known subcommand; an unrecognized one is reported as an
+// error rather than silently falling back to `merge`, which would
+// otherwise swallow typos (and their flags, since flag.Parse stops at the
+// first non-flag token).
+func dispatchCommand(args []string) {
+	cmds := commands()
+
+	name := "merge"
+	rest := args
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = args[0]
+		rest = args[1:]
+	}
+
+	for _, c := range cmds {
+		if c.name != name {
+			continue
+		}
+		c.flags.Parse(rest)
+
+		if *debug {
+			handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+			slog.SetDefault(slog.New(handler))
+		}
+
+		c.run(c.flags.Args())
+		return
+	}
+
+	slog.Error("Unknown command: " + name)
+	printUsage(cmds)
+	os.Exit(1)
+}
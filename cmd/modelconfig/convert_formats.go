@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// convert_formats.go gives `convert --format=yaml|toml` something real to
+// do without pulling in a full yaml/toml library for what config actually
+// needs from either format. These are deliberately NOT general-purpose
+// YAML/TOML parsers: they only understand the shape config actually needs
+// — scalar key/value pairs, plus nested mappings of scalars such as
+// "redeemparams" → "bonk" → fields. Tabs in YAML indentation, lines without
+// a key/value separator, and TOML [[...]] headers are rejected with an
+// error; other syntax (lists, multi-line strings, anchors, inline tables,
+// ...) is not detected, and such a value comes through as a plain string.
+// Both parsers produce a map[string]any using the same types
+// encoding/json would (string, bool, float64), so the result can be
+// json.Marshal'd straight into the bytes decodeConfigBytes already knows
+// how to handle.
+
+// parseYAMLMapping parses an indentation-based "key: value" mapping
+// document into the same map[string]any shape json.Unmarshal would
+// produce. Supports nested mappings (a bare "key:" with no value, followed
+// by more indented lines), to any depth, for redeemparams; nothing else in
+// the YAML spec (lists, flow collections, multi-line scalars, anchors) is
+// recognized.
+func parseYAMLMapping(data []byte) (map[string]any, error) {
+	type frame struct {
+		indent int
+		m      map[string]any
+	}
+
+	root := map[string]any{}
+	stack := []frame{{indent: -1, m: root}}
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := 0
+		for indent < len(line) && (line[indent] == ' ' || line[indent] == '\t') {
+			if line[indent] == '\t' {
+				return nil, fmt.Errorf("line %d: tabs are not supported", lineNo)
+			}
+			indent++
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\", got %q", lineNo, trimmed)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		for len(stack) > 1 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].m
+
+		if value == "" {
+			child := map[string]any{}
+			parent[key] = child
+			stack = append(stack, frame{indent: indent, m: child})
+			continue
+		}
+
+		parent[key] = parseScalar(value, `"'`)
+	}
+
+	return root, nil
+}
+
+// parseTOMLMapping parses "key = value" pairs and "[dotted.table]" headers
+// into the same map[string]any shape json.Unmarshal would produce. Only
+// bare scalar values and dotted table headers are recognized, enough to
+// cover config's nested redeemparams tables; arrays, inline tables,
+// and array-of-tables ([[...]]) are not.
+func parseTOMLMapping(data []byte) (map[string]any, error) {
+	root := map[string]any{}
+	current := root
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: unsupported table header %q", lineNo, line)
+			}
+			current = root
+			for _, part := range strings.Split(line[1:len(line)-1], ".") {
+				part = strings.TrimSpace(part)
+				child, ok := current[part].(map[string]any)
+				if !ok {
+					child = map[string]any{}
+					current[part] = child
+				}
+				current = child
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key = value\", got %q", lineNo, line)
+		}
+		current[strings.TrimSpace(key)] = parseScalar(strings.TrimSpace(value), `"`)
+	}
+
+	return root, nil
+}
+
+// parseScalar converts a bare YAML/TOML scalar token into the string,
+// bool, or float64 it represents, stripping the given quote characters
+// if the value is a quoted string.
+func parseScalar(s string, quotes string) any {
+	if len(s) >= 2 && strings.ContainsRune(quotes, rune(s[0])) && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	// ParseFloat also accepts "NaN"/"Inf"/"Infinity" (any case, optionally
+	// signed), which aren't valid JSON numbers and would make the
+	// json.Marshal in runConvertCommand fail later with a confusing error;
+	// treat those as plain strings instead, same as any other unquoted word.
+	if n, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+		return n
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAMLMapping(t *testing.T) {
+	doc := `
+# a comment, and a blank line above
+software: OBS
+bonk: true
+redeemparams:
+  bonk:
+    enabled: true
+    costpoints: 100
+    weight: 1.5
+`
+	got, err := parseYAMLMapping([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseYAMLMapping: %v", err)
+	}
+
+	want := map[string]any{
+		"software": "OBS",
+		"bonk":     true,
+		"redeemparams": map[string]any{
+			"bonk": map[string]any{
+				"enabled":    true,
+				"costpoints": float64(100),
+				"weight":     1.5,
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestParseYAMLMappingRejectsTabs(t *testing.T) {
+	if _, err := parseYAMLMapping([]byte("redeemparams:\n\tbonk: true\n")); err == nil {
+		t.Fatal("expected an error for a tab-indented line, got nil")
+	}
+}
+
+// TestParseScalarKeepsNonFiniteLookalikesAsStrings guards against
+// strconv.ParseFloat happily accepting "NaN"/"Inf"/"Infinity" as numbers:
+// that would produce a float64 json.Marshal can't encode, turning a
+// literal software/redeem name equal to one of those words into a crash
+// deep in runConvertCommand instead of a plain string value.
+func TestParseScalarKeepsNonFiniteLookalikesAsStrings(t *testing.T) {
+	for _, s := range []string{"NaN", "Inf", "+Inf", "-Inf", "Infinity", "infinity"} {
+		got := parseScalar(s, `"'`)
+		if got != s {
+			t.Errorf("parseScalar(%q) = %#v, want the original string back", s, got)
+		}
+	}
+}
+
+func TestParseTOMLMapping(t *testing.T) {
+	doc := `
+# a comment, and a blank line above
+software = "OBS"
+bonk = true
+
+[redeemparams.bonk]
+enabled = true
+costpoints = 100
+weight = 1.5
+`
+	got, err := parseTOMLMapping([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseTOMLMapping: %v", err)
+	}
+
+	want := map[string]any{
+		"software": "OBS",
+		"bonk":     true,
+		"redeemparams": map[string]any{
+			"bonk": map[string]any{
+				"enabled":    true,
+				"costpoints": float64(100),
+				"weight":     1.5,
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestParseTOMLMappingRejectsArrayOfTables(t *testing.T) {
+	if _, err := parseTOMLMapping([]byte("[[redeemparams]]\nbonk = true\n")); err == nil {
+		t.Fatal("expected an error for an array-of-tables header, got nil")
+	}
+}
@@ -0,0 +1,118 @@
+//go:build ignore
+
+// gen_redeems.go reads the `config` struct in modelconfig.go and writes
+// redeems.go from each bool field's `default`/`merge` struct tags. Invoked
+// via the //go:generate directive above the config struct:
+//
+//	go run gen_redeems.go
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+)
+
+type redeem struct {
+	Name    string
+	JSONKey string
+	Default bool
+	Merge   string
+}
+
+func main() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "modelconfig.go", nil, parser.ParseComments)
+	if err != nil {
+		log.Fatalf("parsing modelconfig.go: %v", err)
+	}
+
+	var redeems []redeem
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != "config" {
+			return true
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		for _, field := range st.Fields.List {
+			if field.Tag == nil || len(field.Names) == 0 {
+				continue
+			}
+			tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+			mergeOp, hasMerge := tag.Lookup("merge")
+			defaultStr, hasDefault := tag.Lookup("default")
+			if !hasMerge || !hasDefault {
+				continue
+			}
+
+			jsonKey := tag.Get("json")
+			if comma := strings.Index(jsonKey, ","); comma >= 0 {
+				jsonKey = jsonKey[:comma]
+			}
+
+			redeems = append(redeems, redeem{
+				Name:    field.Names[0].Name,
+				JSONKey: jsonKey,
+				Default: defaultStr == "true",
+				Merge:   mergeOp,
+			})
+		}
+
+		return false
+	})
+
+	if len(redeems) == 0 {
+		log.Fatal("no tagged redeem fields found in config")
+	}
+
+	out, err := os.Create("redeems.go")
+	if err != nil {
+		log.Fatalf("creating redeems.go: %v", err)
+	}
+	defer out.Close()
+
+	fmt.Fprint(out, "// Code generated by gen_redeems.go from config's `default`/`merge` struct\n")
+	fmt.Fprint(out, "// tags in modelconfig.go; DO NOT EDIT. Run `go generate` to regenerate.\n\n")
+	fmt.Fprint(out, "package main\n\n")
+
+	fmt.Fprint(out, "// Redeem describes one bool field of config: its JSON key, the value\n")
+	fmt.Fprint(out, "// newConfig() starts it at, and how two configs' values for it combine in\n")
+	fmt.Fprint(out, "// mergeConfigs.\n")
+	fmt.Fprint(out, "type Redeem struct {\n\tName    string\n\tJSONKey string\n\tDefault bool\n\tMerge   string // \"and\" or \"or\"\n}\n\n")
+
+	fmt.Fprint(out, "var Redeems = []Redeem{\n")
+	for _, r := range redeems {
+		fmt.Fprintf(out, "\t{Name: %q, JSONKey: %q, Default: %t, Merge: %q},\n", r.Name, r.JSONKey, r.Default, r.Merge)
+	}
+	fmt.Fprint(out, "}\n\n")
+
+	fmt.Fprint(out, "// redeemField returns a pointer to the bool field on c named by r.Name.\n")
+	fmt.Fprint(out, "// Kept to a closed switch (rather than reflection) so the merge hot path\n")
+	fmt.Fprint(out, "// is statically dispatched.\n")
+	fmt.Fprint(out, "func redeemField(c *config, name string) *bool {\n\tswitch name {\n")
+	for _, r := range redeems {
+		fmt.Fprintf(out, "\tcase %q:\n\t\treturn &c.%s\n", r.Name, r.Name)
+	}
+	fmt.Fprint(out, "\tdefault:\n\t\treturn nil\n\t}\n}\n\n")
+
+	fmt.Fprint(out, "// ApplyRedeemDefaults sets every redeem on c to its Redeems default.\n")
+	fmt.Fprint(out, "func ApplyRedeemDefaults(c *config) {\n\tfor _, r := range Redeems {\n\t\t*redeemField(c, r.Name) = r.Default\n\t}\n}\n\n")
+
+	fmt.Fprint(out, "// MergeRedeems resolves every redeem of o and n per its Merge policy and\n")
+	fmt.Fprint(out, "// returns o with the results applied.\n")
+	fmt.Fprint(out, "func MergeRedeems(o config, n config) config {\n\tfor _, r := range Redeems {\n\t\tof := redeemField(&o, r.Name)\n\t\tnf := *redeemField(&n, r.Name)\n\t\tswitch r.Merge {\n\t\tcase \"or\":\n\t\t\t*of = *of || nf\n\t\tdefault: // \"and\"\n\t\t\t*of = *of && nf\n\t\t}\n\t}\n\treturn o\n}\n\n")
+
+	fmt.Fprint(out, "// DisableAllRedeems sets every redeem on c to false, e.g. when no model\n")
+	fmt.Fprint(out, "// config was found and nothing should be enabled.\n")
+	fmt.Fprint(out, "func DisableAllRedeems(c *config) {\n\tfor _, r := range Redeems {\n\t\t*redeemField(c, r.Name) = false\n\t}\n}\n")
+}
@@ -0,0 +1,538 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+)
+
+var (
+	debug      = flag.Bool("debug", false, "Print debugging info.")
+	modelFile  = flag.String("modelFile", "", "Model file of config we're loading.")
+	configRoot = flag.String(
+		"configRoot",
+		"G:\\My Drive\\Streaming\\Chatbot\\model_configs\\",
+		"Root folder where configs are found.",
+	)
+	writeJSONFile = flag.Bool("writeJSONFile", true, "Write a JSON file?")
+	outFile       = flag.String(
+		"outFile",
+		"D:\\Temp\\model_config.json",
+		"The output file we write merged configs to.",
+	)
+	writeSchema = flag.Bool("writeSchema", false, "Write a schema file?")
+	schemaFile  = flag.String(
+		"schemaFile",
+		"G:\\My Drive\\Streaming\\Chatbot\\model_configs\\schema.json",
+		"The schema file used to validate configs.",
+	)
+	strictSchema = flag.Bool(
+		"strictSchema",
+		false,
+		"Abort with a non-zero exit code listing every schema violation instead of just logging a warning.",
+	)
+	watch = flag.Bool(
+		"watch",
+		false,
+		"Keep running after the initial merge, re-merging and rewriting outFile whenever a contributing file changes.",
+	)
+	watchAddr = flag.String(
+		"watchAddr",
+		"",
+		"If set, serve /config and /reload over HTTP at this address while --watch is running.",
+	)
+	// A list of all include files read by filename to avoid processing duplicates.
+	// Mostly as a cheap backstop to prevent a recursive loop of includes.
+	includesSeen = map[string]bool{}
+)
+
+//go:generate go run gen_redeems.go
+
+// config's bool fields are redeems: `default` is what newConfig() starts
+// them at, and `merge` is how two configs combine that field (`and`: both
+// must allow it; `or`: either can turn it on). redeems.go is generated
+// from these tags by gen_redeems.go — edit the tag, not redeems.go.
+type config struct {
+	// Includes
+	Include string `json:"include"`
+	// Control
+	ConfigFound   bool   `json:"configfound" default:"false" merge:"or"`
+	ModelFileName string `json:"modelfilename"`
+	Software      string `json:"software"`
+	// Redeems
+	AnvilDrop     bool `json:"anvildrop" default:"true" merge:"and"`
+	ASCIIRed      bool `json:"asciired" default:"true" merge:"and"`
+	Bonk          bool `json:"bonk" default:"true" merge:"and"`
+	Boop          bool `json:"boop" default:"true" merge:"and"`
+	Chaos         bool `json:"chaos" default:"true" merge:"and"`
+	Feets         bool `json:"feets" default:"true" merge:"and"`
+	Fisheye       bool `json:"fisheye" default:"true" merge:"and"`
+	Headpats      bool `json:"headpats" default:"true" merge:"and"`
+	NoGlasses     bool `json:"noglasses" default:"true" merge:"and"`
+	NuggiesForRed bool `json:"nuggiesforred" default:"true" merge:"and"`
+	PeltThePanda  bool `json:"peltthepanda" default:"true" merge:"and"`
+	PieDrop       bool `json:"piedrop" default:"true" merge:"and"`
+	PostItRed     bool `json:"postitred" default:"true" merge:"and"`
+	RedInABox     bool `json:"redinabox" default:"true" merge:"and"`
+	RentThisHat   bool `json:"rentthishat" default:"true" merge:"and"`
+	SpinThePanda  bool `json:"spinthepanda" default:"true" merge:"and"`
+	SprayBottle   bool `json:"spraybottle" default:"true" merge:"and"`
+	SuspiciousRed bool `json:"suspiciousred" default:"true" merge:"and"`
+	SwolePanda    bool `json:"swolepanda" default:"true" merge:"and"`
+	Tail          bool `json:"tail" default:"true" merge:"and"`
+	TimeWarpScan  bool `json:"timewarpscan" default:"true" merge:"and"`
+	ToughLove     bool `json:"toughlove" default:"true" merge:"and"`
+	// Optional non-bool parameters for redeems that need a cost, cooldown,
+	// or weight on top of the plain on/off above, keyed by the same name
+	// as the JSON key above (e.g. "bonk"). A redeem with no entry here
+	// just uses its bool as-is; StreamerBot only needs to read these if
+	// it cares about cost/cooldown/weight for that redeem.
+	RedeemParams map[string]RedeemParams `json:"redeemparams,omitempty"`
+}
+
+// RedeemParams carries the non-bool knobs a redeem can have. Each field's
+// `merge` tag is honored by mergeRedeemParams, same vocabulary as the
+// `merge` tag on config's own bool fields. CostPoints and Weight are
+// pointers, not plain int/float64, because a zero value (free redeem, zero
+// weight) has to be distinguishable from "this file doesn't set it", or an
+// explicit zero could never override a nonzero value set elsewhere.
+type RedeemParams struct {
+	Enabled     bool     `json:"enabled" merge:"and"`
+	CostPoints  *int     `json:"costpoints,omitempty" merge:"min"`
+	CooldownSec int      `json:"cooldownsec,omitempty" merge:"max"`
+	Weight      *float64 `json:"weight,omitempty" merge:"override"`
+}
+
+// mergeRedeemParams resolves one redeem's params per-field, using each
+// field's `merge` tag: min/max pick the more conservative number, override
+// takes n's value when set, and/or combine bools the same way MergeRedeems
+// does for the flat redeem bools. min and override are pointer fields, so
+// "set" is nil-checked rather than zero-checked.
+func mergeRedeemParams(o RedeemParams, n RedeemParams) RedeemParams {
+	t := reflect.TypeOf(o)
+	ov := reflect.ValueOf(&o).Elem()
+	nv := reflect.ValueOf(n)
+
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("merge")
+		of := ov.Field(i)
+		nf := nv.Field(i)
+
+		switch tag {
+		case "and":
+			of.SetBool(of.Bool() && nf.Bool())
+		case "min":
+			if nf.IsNil() {
+				continue
+			}
+			if of.IsNil() || nf.Elem().Int() < of.Elem().Int() {
+				of.Set(nf)
+			}
+		case "max":
+			if nf.Int() > of.Int() {
+				of.SetInt(nf.Int())
+			}
+		case "override":
+			if !nf.IsNil() {
+				of.Set(nf)
+			}
+		}
+	}
+
+	return o
+}
+
+// mergeRedeemParamsMaps merges every key present in either o or n, so a
+// redeem's params can be set in one file and tightened in another without
+// either side needing to repeat the other's numeric fields. Enabled is
+// and-ed, so a file that has an entry for a redeem but leaves out
+// "enabled" turns it off.
+func mergeRedeemParamsMaps(o map[string]RedeemParams, n map[string]RedeemParams) map[string]RedeemParams {
+	if len(o) == 0 && len(n) == 0 {
+		return nil
+	}
+
+	merged := map[string]RedeemParams{}
+	for k, v := range o {
+		merged[k] = v
+	}
+	for k, v := range n {
+		if existing, ok := merged[k]; ok {
+			merged[k] = mergeRedeemParams(existing, v)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func newConfig() config {
+	// ConfigFound defaults to false (the zero value); every other redeem's
+	// default comes from the generated Redeems table.
+	config := config{}
+	ApplyRedeemDefaults(&config)
+	return config
+}
+
+// readFromFile loads and decodes a config file, then validates the raw
+// decode against buildSchema() so a misspelled redeem name (e.g.
+// "headpatz") is caught instead of silently dropped by reflection-based
+// decoding into the config struct. In --strictSchema mode, any violation
+// aborts the program; otherwise each violation is just a slog warning and
+// the (already reflection-decoded) config is still used as-is.
+func readFromFile(f string) config {
+	configFile, err := os.Open(f)
+	if err != nil {
+		slog.Debug("Error loading config:", err.Error(), err)
+		c := newConfig()
+		c.ConfigFound = false
+		return c
+	}
+	defer configFile.Close()
+
+	data, err := io.ReadAll(configFile)
+	if err != nil {
+		slog.Debug("Error reading config:", err.Error(), err)
+		c := newConfig()
+		c.ConfigFound = false
+		return c
+	}
+
+	return decodeConfigBytes(data, f)
+}
+
+// decodeConfigBytes decodes already-JSON-shaped bytes into a config and
+// validates the raw decode against buildSchema(), same as readFromFile;
+// pulled out separately so runConvertCommand can feed it bytes that
+// started out as YAML or TOML and were already converted to JSON.
+func decodeConfigBytes(data []byte, source string) config {
+	c := newConfig()
+	c.ConfigFound = true
+
+	json.Unmarshal(data, &c)
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err == nil {
+		violations := validateAgainstSchema(buildSchema(), raw)
+		if len(violations) > 0 {
+			if *strictSchema {
+				slog.Error(fmt.Sprintf("Schema violations in %s:", source))
+				for _, v := range violations {
+					slog.Error("  " + v)
+				}
+				os.Exit(1)
+			}
+			for _, v := range violations {
+				slog.Warn(fmt.Sprintf("Schema violation in %s: %s", source, v))
+			}
+		}
+	}
+
+	return c
+}
+
+func writeToFile(f string, c config) {
+	// Write the merged data to a new JSON file.
+	outputFile, err := os.Create(f)
+	if err != nil {
+		slog.Debug("Error creating config file:", err.Error(), err)
+	}
+	defer outputFile.Close()
+
+	output, _ := json.MarshalIndent(c, "", "  ")
+
+	_, err = outputFile.Write(output)
+	if err != nil {
+		slog.Debug("Error writing config file:", err.Error(), err)
+	}
+
+	outputFile.Sync()
+
+	w := bufio.NewWriter(outputFile)
+	w.Flush()
+}
+
+// mergeConfigs merges n into o and also returns every include location
+// (recursively) that contributed to the result, so --watch can tell which
+// files on disk it needs to keep an eye on.
+func mergeConfigs(o config, n config) (config, []string) {
+	sources := []string{}
+
+	// Keep include processing first!
+	// Reason being to have original take precedent over the include.
+	// (Last config applied wins.)
+	if n.Include != "" {
+		// A bare name (no scheme, no trailing slash) is the original
+		// "includes\<name>.json" local-file convention. Anything that
+		// classifies as a URL or a directory is used as-is.
+		loc := n.Include
+		if classifyInclude(loc) == includeFile {
+			loc = fmt.Sprintf("%sincludes\\%s.json", *configRoot, n.Include)
+		}
+
+		key := canonicalIncludeKey(loc)
+		// Skip if we've read this location before.
+		if !includesSeen[key] {
+			includesSeen[key] = true
+			i := readInclude(loc)
+
+			if i.ConfigFound {
+				slog.Debug("    Inlcuded " + n.Include + " configs...")
+				var includeSources []string
+				o, includeSources = mergeConfigs(o, i)
+				sources = append(sources, loc)
+				sources = append(sources, includeSources...)
+			}
+		} else {
+			slog.Debug("    Already seen " + n.Include + " in another config...")
+		}
+	}
+
+	o = MergeRedeems(o, n)
+	o.RedeemParams = mergeRedeemParamsMaps(o.RedeemParams, n.RedeemParams)
+
+	// Export which software we've selected.
+	// I assume this could be useful. At least for troubleshooting.
+	if n.Software != "" {
+		o.Software = n.Software
+	}
+
+	return o, sources
+}
+
+func applyOverrides(c config) config {
+	// Values that don't need to be passed into StreamerBot.
+	c.Include = ""
+
+	// No config found means no model is found.
+	// Disable all redeems.
+	if !c.ConfigFound {
+		DisableAllRedeems(&c)
+	}
+
+	return c
+}
+
+func sanitizeModelFileName(f string) string {
+	// We're expecting a filename.ext format.
+	// We want to return just the filename without ext.
+	s := strings.Split(f, ".")
+	return s[0]
+}
+
+// schemaProperty is one property entry of a JSON Schema document. Object
+// types (structs) set Properties; a map of objects sets AdditionalProperties
+// to the value type's schema instead.
+type schemaProperty struct {
+	Type                 string                    `json:"type"`
+	Properties           map[string]schemaProperty `json:"properties,omitempty"`
+	AdditionalProperties *schemaProperty           `json:"additionalProperties,omitempty"`
+}
+
+// jsonSchemaType maps a Go reflect.Kind to its JSON Schema type name.
+func jsonSchemaType(k reflect.Kind) string {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// schemaForType builds a schemaProperty for t, recursing into struct
+// fields and map value types so nested redeem params get their own typed
+// properties instead of a bare "object". Pointer fields (used by
+// RedeemParams so a merge strategy can tell "unset" apart from a real
+// zero value) are unwrapped transparently: the schema describes the
+// pointed-to type, since that's what config authors actually write.
+func schemaForType(t reflect.Type) schemaProperty {
+	if t.Kind() == reflect.Ptr {
+		return schemaForType(t.Elem())
+	}
+
+	prop := schemaProperty{Type: jsonSchemaType(t.Kind())}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		prop.Properties = map[string]schemaProperty{}
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.ToLower(t.Field(i).Name)
+			prop.Properties[name] = schemaForType(t.Field(i).Type)
+		}
+	case reflect.Map:
+		elemSchema := schemaForType(t.Elem())
+		prop.AdditionalProperties = &elemSchema
+	}
+
+	return prop
+}
+
+// schema is an in-memory JSON Schema for config, built once by buildSchema
+// and used both to write schema.json and to validate loaded config files,
+// so the two can't drift apart the way two independent code paths would.
+type schema struct {
+	Schema               string                    `json:"$schema,omitempty"`
+	Type                 string                    `json:"type"`
+	AdditionalProperties bool                      `json:"additionalProperties"`
+	Properties           map[string]schemaProperty `json:"properties"`
+}
+
+// buildSchema reflects over config to build the schema that both
+// writeSchemaFile and validateAgainstSchema use.
+func buildSchema() *schema {
+	s := &schema{
+		Schema:               "http://json-schema.org/draft-07/schema#",
+		Type:                 "object",
+		AdditionalProperties: false,
+		Properties: map[string]schemaProperty{
+			"_comment": {Type: "string"},
+			"$schema":  {Type: "string"},
+		},
+	}
+
+	t := reflect.TypeOf(newConfig())
+	for i := 0; i < t.NumField(); i++ {
+		n := strings.ToLower(t.Field(i).Name)
+		s.Properties[n] = schemaForType(t.Field(i).Type)
+	}
+
+	return s
+}
+
+// validateAgainstSchema flags any field in raw that buildSchema's
+// additionalProperties:false doesn't recognize, catching typos like
+// "headpatz" that today get silently dropped when the struct is decoded.
+func validateAgainstSchema(s *schema, raw map[string]any) []string {
+	violations := []string{}
+	for k := range raw {
+		if _, ok := s.Properties[k]; !ok {
+			violations = append(violations, "/"+k+": unknown field, not in schema")
+		}
+	}
+	return violations
+}
+
+func writeSchemaFile() {
+	f := *schemaFile
+	s := buildSchema()
+
+	outputFile, err := os.Create(f)
+	if err != nil {
+		slog.Debug("Error creating schema file:", err.Error(), err)
+	}
+	defer outputFile.Close()
+
+	output, _ := json.MarshalIndent(s, "", "  ")
+
+	_, err = outputFile.Write(output)
+	if err != nil {
+		slog.Debug("Error writing config file:", err.Error(), err)
+	}
+
+	outputFile.Sync()
+
+	w := bufio.NewWriter(outputFile)
+	w.Flush()
+}
+
+// buildMergedModelConfig resolves --modelFile, merges in every include it
+// pulls in, and applies overrides, returning the result plus every file
+// location that contributed (model file first, then each include followed
+// by the includes it pulled in; nested includes are merged before the file
+// that names them). includesSeen is reset first so repeated calls (as
+// --watch makes on every reload) don't skip includes as "already seen".
+func buildMergedModelConfig() (config, []string) {
+	// --modelFile is normally a bare model name under configRoot, but it
+	// may also be an HTTP(S) URL or a pack directory, same as `include`.
+	saneModelFile := sanitizeModelFileName(*modelFile)
+	modelFileName := fmt.Sprintf("%s%s.json", *configRoot, saneModelFile)
+	if classifyInclude(*modelFile) != includeFile {
+		modelFileName = *modelFile
+	}
+
+	includesSeen = map[string]bool{}
+
+	// Read the JSON files into data structures.
+	slog.Debug("Reading configs...")
+	modelConfig := readInclude(modelFileName)
+
+	// Combine the JSON files with preference for gameConfig.
+	// Included/Nested configs will be recursed during each merge.
+	slog.Debug("Merging configs...")
+	config := newConfig()
+	sources := []string{modelFileName}
+
+	// global
+	if modelConfig.ConfigFound {
+		slog.Debug("  Model configs...")
+		var includeSources []string
+		config, includeSources = mergeConfigs(config, modelConfig)
+		sources = append(sources, includeSources...)
+	}
+
+	// Set ConfigFound to model's setting before we apply overrides.
+	config.ConfigFound = modelConfig.ConfigFound
+
+	// Apply overrides.
+	config = applyOverrides(config)
+
+	// Things we need to set after all is said and done.
+	// Typically things we can't do in the applyOverrides scope.
+	config.ModelFileName = saneModelFile
+
+	return config, sources
+}
+
+// writeMergedModelConfig writes config to --outFile (if enabled) and
+// stdout, the shared tail end of both the one-shot and --watch paths.
+func writeMergedModelConfig(config config) {
+	if *writeJSONFile {
+		slog.Debug("Writing JSON file...")
+		writeToFile(*outFile, config)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(config); err != nil {
+		panic(err)
+	}
+}
+
+// runMergeCommand is the `merge` subcommand (also the default when invoked
+// with no subcommand name, for backward compatibility with the original
+// flag-only CLI).
+func runMergeCommand() {
+	if *modelFile == "" {
+		slog.Error("--modelFile flag required.")
+		os.Exit(1)
+	}
+
+	config, sources := buildMergedModelConfig()
+	writeMergedModelConfig(config)
+
+	// Write out JSON schema.
+	if *writeSchema {
+		slog.Debug("Writing schema file...")
+		writeSchemaFile()
+	}
+
+	if *watch {
+		watchAndReload(sources, config)
+	}
+
+	slog.Debug("End of Line.")
+}
+
+func main() {
+	dispatchCommand(os.Args[1:])
+}
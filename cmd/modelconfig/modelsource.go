@@ -0,0 +1,195 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// modelsource.go generalizes what a model config's --modelFile and
+// `include` values can point at: a plain local file (the original
+// behavior), an HTTP(S) URL, or a directory of *.json files merged in
+// lexicographic order. This lets streamers share redeem bundles ("packs")
+// without everyone mirroring the same Google Drive layout, e.g.
+// `include: "https://example.com/base.json"` or `include: "packs/combat/"`.
+
+// includeKind classifies a location string so readInclude knows which
+// loader to use.
+type includeKind int
+
+const (
+	includeFile includeKind = iota
+	includeHTTP
+	includeDir
+)
+
+// classifyInclude looks at loc's shape to decide how to load it. A
+// trailing "/**" means "directory, recursive"; a trailing "/" means
+// "directory, this level only"; an http(s):// prefix means a URL;
+// anything else is a local file.
+func classifyInclude(loc string) includeKind {
+	if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+		return includeHTTP
+	}
+	if strings.HasSuffix(loc, "/") || strings.HasSuffix(loc, "/**") {
+		return includeDir
+	}
+	return includeFile
+}
+
+// canonicalIncludeKey normalizes loc so the same URL or directory spelled
+// two different ways still dedupes in includesSeen.
+func canonicalIncludeKey(loc string) string {
+	switch classifyInclude(loc) {
+	case includeHTTP:
+		if u, err := url.Parse(loc); err == nil {
+			// url.Parse already lowercases the scheme; hosts are
+			// case-insensitive too.
+			u.Host = strings.ToLower(u.Host)
+			return u.String()
+		}
+		return loc
+	case includeDir:
+		return filepath.Clean(strings.TrimSuffix(loc, "**")) + string(filepath.Separator)
+	default:
+		return filepath.Clean(loc)
+	}
+}
+
+// readInclude loads and merges whatever loc points at into a single
+// config, honoring whichever of includeFile/includeHTTP/includeDir it
+// classifies as.
+func readInclude(loc string) config {
+	switch classifyInclude(loc) {
+	case includeHTTP:
+		return readFromURL(loc)
+	case includeDir:
+		return readFromDir(loc)
+	default:
+		return readFromFile(loc)
+	}
+}
+
+// cacheDir holds the on-disk HTTP cache, keyed by URL so repeated bot
+// starts don't re-fetch packs that haven't changed.
+func cacheDir() string {
+	return *configRoot + "cache\\"
+}
+
+// cachePaths returns the body and ETag sidecar paths for a URL's cache
+// entry, named after a SHA256 of the URL so arbitrary URLs are safe
+// filenames.
+func cachePaths(rawURL string) (body string, etag string) {
+	sum := sha256.Sum256([]byte(rawURL))
+	key := hex.EncodeToString(sum[:])
+	return cacheDir() + key + ".json", cacheDir() + key + ".etag"
+}
+
+// readFromURL fetches rawURL, using a cached copy (revalidated with
+// If-None-Match) when one exists, and decodes the result into a config.
+func readFromURL(rawURL string) config {
+	c := newConfig()
+
+	bodyPath, etagPath := cachePaths(rawURL)
+	cachedETag, _ := os.ReadFile(etagPath)
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		slog.Debug("Error building request for " + rawURL + ": " + err.Error())
+		c.ConfigFound = false
+		return c
+	}
+	if len(cachedETag) > 0 {
+		req.Header.Set("If-None-Match", string(cachedETag))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		slog.Debug("Error fetching " + rawURL + ": " + err.Error())
+		return readFromFile(bodyPath)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		slog.Debug("Using cached copy of " + rawURL)
+		return readFromFile(bodyPath)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		slog.Debug(fmt.Sprintf("Unexpected status %d fetching %s", resp.StatusCode, rawURL))
+		c.ConfigFound = false
+		return c
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		slog.Debug("Error reading body of " + rawURL + ": " + err.Error())
+		c.ConfigFound = false
+		return c
+	}
+
+	if err := os.MkdirAll(cacheDir(), 0o755); err == nil {
+		os.WriteFile(bodyPath, data, 0o644)
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			os.WriteFile(etagPath, []byte(etag), 0o644)
+		}
+	}
+
+	return readFromFile(bodyPath)
+}
+
+// readFromDir loads every *.json file directly under loc (or, for a "/**"
+// suffix, every *.json file beneath it recursively) and merges them in
+// lexicographic order, last file winning ties same as everywhere else.
+func readFromDir(loc string) config {
+	recursive := strings.HasSuffix(loc, "/**")
+	dir := strings.TrimSuffix(strings.TrimSuffix(loc, "**"), "/")
+
+	var files []string
+	walkErr := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if !recursive && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	c := newConfig()
+	if walkErr != nil {
+		slog.Debug("Error listing pack directory " + dir + ": " + walkErr.Error())
+		c.ConfigFound = false
+		return c
+	}
+
+	sort.Strings(files)
+
+	c.ConfigFound = false
+	for _, f := range files {
+		i := readFromFile(f)
+		if i.ConfigFound {
+			// Sources within a pack directory aren't individually tracked
+			// for --watch; the directory itself is watched as one unit
+			// (see mergeConfigs's include handling).
+			c, _ = mergeConfigs(c, i)
+			c.ConfigFound = true
+		}
+	}
+
+	return c
+}
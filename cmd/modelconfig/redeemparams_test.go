@@ -0,0 +1,111 @@
+package main
+
+import "testing"
+
+func intPtr(i int) *int           { return &i }
+func floatPtr(f float64) *float64 { return &f }
+
+func TestMergeRedeemParams(t *testing.T) {
+	cases := []struct {
+		name string
+		o    RedeemParams
+		n    RedeemParams
+		want RedeemParams
+	}{
+		{
+			"enabled is and'd",
+			RedeemParams{Enabled: true},
+			RedeemParams{Enabled: false},
+			RedeemParams{Enabled: false},
+		},
+		{
+			"costpoints min takes the lower of two set values",
+			RedeemParams{CostPoints: intPtr(500)},
+			RedeemParams{CostPoints: intPtr(100)},
+			RedeemParams{CostPoints: intPtr(100)},
+		},
+		{
+			"costpoints explicit zero overrides a nonzero prior value",
+			RedeemParams{CostPoints: intPtr(500)},
+			RedeemParams{CostPoints: intPtr(0)},
+			RedeemParams{CostPoints: intPtr(0)},
+		},
+		{
+			"costpoints unset in the incoming file keeps the prior value",
+			RedeemParams{CostPoints: intPtr(500)},
+			RedeemParams{CostPoints: nil},
+			RedeemParams{CostPoints: intPtr(500)},
+		},
+		{
+			"cooldownsec max keeps the larger of two set values",
+			RedeemParams{CooldownSec: 30},
+			RedeemParams{CooldownSec: 60},
+			RedeemParams{CooldownSec: 60},
+		},
+		{
+			"weight override takes the incoming value when set",
+			RedeemParams{Weight: floatPtr(2)},
+			RedeemParams{Weight: floatPtr(0.5)},
+			RedeemParams{Weight: floatPtr(0.5)},
+		},
+		{
+			"weight explicit zero overrides a nonzero prior value",
+			RedeemParams{Weight: floatPtr(2)},
+			RedeemParams{Weight: floatPtr(0)},
+			RedeemParams{Weight: floatPtr(0)},
+		},
+		{
+			"weight unset in the incoming file keeps the prior value",
+			RedeemParams{Weight: floatPtr(2)},
+			RedeemParams{Weight: nil},
+			RedeemParams{Weight: floatPtr(2)},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := mergeRedeemParams(c.o, c.n)
+
+			if got.Enabled != c.want.Enabled {
+				t.Errorf("Enabled = %v, want %v", got.Enabled, c.want.Enabled)
+			}
+			if got.CooldownSec != c.want.CooldownSec {
+				t.Errorf("CooldownSec = %v, want %v", got.CooldownSec, c.want.CooldownSec)
+			}
+			if !ptrIntEqual(got.CostPoints, c.want.CostPoints) {
+				t.Errorf("CostPoints = %v, want %v", derefInt(got.CostPoints), derefInt(c.want.CostPoints))
+			}
+			if !ptrFloatEqual(got.Weight, c.want.Weight) {
+				t.Errorf("Weight = %v, want %v", derefFloat(got.Weight), derefFloat(c.want.Weight))
+			}
+		})
+	}
+}
+
+func ptrIntEqual(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func ptrFloatEqual(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func derefInt(p *int) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func derefFloat(p *float64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
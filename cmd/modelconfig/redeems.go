@@ -0,0 +1,127 @@
+// Code generated by gen_redeems.go from config's `default`/`merge` struct
+// tags in modelconfig.go; DO NOT EDIT. Run `go generate` to regenerate.
+
+package main
+
+// Redeem describes one bool field of config: its JSON key, the value
+// newConfig() starts it at, and how two configs' values for it combine in
+// mergeConfigs.
+type Redeem struct {
+	Name    string
+	JSONKey string
+	Default bool
+	Merge   string // "and" or "or"
+}
+
+var Redeems = []Redeem{
+	{Name: "ConfigFound", JSONKey: "configfound", Default: false, Merge: "or"},
+	{Name: "AnvilDrop", JSONKey: "anvildrop", Default: true, Merge: "and"},
+	{Name: "ASCIIRed", JSONKey: "asciired", Default: true, Merge: "and"},
+	{Name: "Bonk", JSONKey: "bonk", Default: true, Merge: "and"},
+	{Name: "Boop", JSONKey: "boop", Default: true, Merge: "and"},
+	{Name: "Chaos", JSONKey: "chaos", Default: true, Merge: "and"},
+	{Name: "Feets", JSONKey: "feets", Default: true, Merge: "and"},
+	{Name: "Fisheye", JSONKey: "fisheye", Default: true, Merge: "and"},
+	{Name: "Headpats", JSONKey: "headpats", Default: true, Merge: "and"},
+	{Name: "NoGlasses", JSONKey: "noglasses", Default: true, Merge: "and"},
+	{Name: "NuggiesForRed", JSONKey: "nuggiesforred", Default: true, Merge: "and"},
+	{Name: "PeltThePanda", JSONKey: "peltthepanda", Default: true, Merge: "and"},
+	{Name: "PieDrop", JSONKey: "piedrop", Default: true, Merge: "and"},
+	{Name: "PostItRed", JSONKey: "postitred", Default: true, Merge: "and"},
+	{Name: "RedInABox", JSONKey: "redinabox", Default: true, Merge: "and"},
+	{Name: "RentThisHat", JSONKey: "rentthishat", Default: true, Merge: "and"},
+	{Name: "SpinThePanda", JSONKey: "spinthepanda", Default: true, Merge: "and"},
+	{Name: "SprayBottle", JSONKey: "spraybottle", Default: true, Merge: "and"},
+	{Name: "SuspiciousRed", JSONKey: "suspiciousred", Default: true, Merge: "and"},
+	{Name: "SwolePanda", JSONKey: "swolepanda", Default: true, Merge: "and"},
+	{Name: "Tail", JSONKey: "tail", Default: true, Merge: "and"},
+	{Name: "TimeWarpScan", JSONKey: "timewarpscan", Default: true, Merge: "and"},
+	{Name: "ToughLove", JSONKey: "toughlove", Default: true, Merge: "and"},
+}
+
+// redeemField returns a pointer to the bool field on c named by r.Name.
+// Kept to a closed switch (rather than reflection) so the merge hot path
+// is statically dispatched.
+func redeemField(c *config, name string) *bool {
+	switch name {
+	case "ConfigFound":
+		return &c.ConfigFound
+	case "AnvilDrop":
+		return &c.AnvilDrop
+	case "ASCIIRed":
+		return &c.ASCIIRed
+	case "Bonk":
+		return &c.Bonk
+	case "Boop":
+		return &c.Boop
+	case "Chaos":
+		return &c.Chaos
+	case "Feets":
+		return &c.Feets
+	case "Fisheye":
+		return &c.Fisheye
+	case "Headpats":
+		return &c.Headpats
+	case "NoGlasses":
+		return &c.NoGlasses
+	case "NuggiesForRed":
+		return &c.NuggiesForRed
+	case "PeltThePanda":
+		return &c.PeltThePanda
+	case "PieDrop":
+		return &c.PieDrop
+	case "PostItRed":
+		return &c.PostItRed
+	case "RedInABox":
+		return &c.RedInABox
+	case "RentThisHat":
+		return &c.RentThisHat
+	case "SpinThePanda":
+		return &c.SpinThePanda
+	case "SprayBottle":
+		return &c.SprayBottle
+	case "SuspiciousRed":
+		return &c.SuspiciousRed
+	case "SwolePanda":
+		return &c.SwolePanda
+	case "Tail":
+		return &c.Tail
+	case "TimeWarpScan":
+		return &c.TimeWarpScan
+	case "ToughLove":
+		return &c.ToughLove
+	default:
+		return nil
+	}
+}
+
+// ApplyRedeemDefaults sets every redeem on c to its Redeems default.
+func ApplyRedeemDefaults(c *config) {
+	for _, r := range Redeems {
+		*redeemField(c, r.Name) = r.Default
+	}
+}
+
+// MergeRedeems resolves every redeem of o and n per its Merge policy and
+// returns o with the results applied.
+func MergeRedeems(o config, n config) config {
+	for _, r := range Redeems {
+		of := redeemField(&o, r.Name)
+		nf := *redeemField(&n, r.Name)
+		switch r.Merge {
+		case "or":
+			*of = *of || nf
+		default: // "and"
+			*of = *of && nf
+		}
+	}
+	return o
+}
+
+// DisableAllRedeems sets every redeem on c to false, e.g. when no model
+// config was found and nothing should be enabled.
+func DisableAllRedeems(c *config) {
+	for _, r := range Redeems {
+		*redeemField(c, r.Name) = false
+	}
+}
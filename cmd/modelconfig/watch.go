@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// watch.go backs --watch: after the initial merge+write, keep running and
+// re-merge/re-write outFile whenever any contributing file changes on
+// disk. Changes are picked up via a real fsnotify watch (startFSWatch, in
+// watch_fsnotify.go) for immediate reaction, with a poll on the fixed
+// interval below as a backstop for filesystems fsnotify can't see and for
+// directories that don't exist yet; that also means day/date rollovers
+// that don't touch any file are missed on the polling path, which is fine
+// here since modelconfig has no such rollover layering to begin with.
+
+const watchPollInterval = 200 * time.Millisecond
+
+// watchState is the latest merged config and its contributing sources,
+// shared between the poll loop and the optional /config and /reload
+// HTTP handlers.
+type watchState struct {
+	mu      sync.Mutex
+	config  config
+	sources []string
+}
+
+func (s *watchState) get() (config, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config, s.sources
+}
+
+func (s *watchState) set(c config, sources []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = c
+	s.sources = sources
+}
+
+// snapshotMtimes records the last-modified time of every source so the
+// next poll can tell whether any of them changed.
+func snapshotMtimes(sources []string) map[string]time.Time {
+	snap := make(map[string]time.Time, len(sources))
+	for _, src := range sources {
+		if info, err := os.Stat(src); err == nil {
+			snap[src] = info.ModTime()
+		}
+	}
+	return snap
+}
+
+// mtimesChanged reports whether any source's mtime differs from old, or a
+// source that had one can no longer be stat'd. Sources that never had an
+// mtime (URLs, "/**" pack locations) don't count as changed.
+func mtimesChanged(old map[string]time.Time, sources []string) bool {
+	for _, src := range sources {
+		prev, ok := old[src]
+		info, err := os.Stat(src)
+		if err != nil {
+			if ok {
+				return true
+			}
+			continue
+		}
+		if !ok || !prev.Equal(info.ModTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffBools reports which bool redeems flipped between old and new, for
+// the reload log line.
+func diffBools(old config, new config) []string {
+	diffs := []string{}
+	t := reflect.TypeOf(config{})
+	ov := reflect.ValueOf(old)
+	nv := reflect.ValueOf(new)
+
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Kind() != reflect.Bool {
+			continue
+		}
+		ob := ov.Field(i).Bool()
+		nb := nv.Field(i).Bool()
+		if ob != nb {
+			diffs = append(diffs, fmt.Sprintf("%s: %v -> %v", t.Field(i).Name, ob, nb))
+		}
+	}
+
+	return diffs
+}
+
+// watchAndReload watches the files that contributed to config (as returned
+// by buildMergedModelConfig) and, on any change, rebuilds and rewrites
+// outFile, logging which redeems flipped. It blocks forever, mirroring how
+// --serve runs in botconfig. Changes are picked up two ways: an fsnotify
+// signal from startFSWatch (immediate) and an mtime poll on
+// watchPollInterval (the universal fallback) — either one triggers the same
+// reload check.
+func watchAndReload(sources []string, initial config) {
+	state := &watchState{config: initial, sources: sources}
+
+	if *watchAddr != "" {
+		go serveWatchHTTP(state)
+	}
+
+	mtimes := snapshotMtimes(sources)
+
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	fsEvents := startFSWatch(watchCtx, sources)
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	reload := func() {
+		current, currentSources := state.get()
+		if !mtimesChanged(mtimes, currentSources) {
+			return
+		}
+
+		slog.Debug("Contributing file changed, reloading...")
+		next, nextSources := buildMergedModelConfig()
+
+		flipped := diffBools(current, next)
+		if len(flipped) > 0 {
+			slog.Info("Redeems changed on reload: " + strings.Join(flipped, ", "))
+		}
+
+		writeMergedModelConfig(next)
+		state.set(next, nextSources)
+		mtimes = snapshotMtimes(nextSources)
+
+		// The set of contributing files (includes added/removed) may have
+		// changed, so re-arm the watch against the new source list.
+		cancelWatch()
+		watchCtx, cancelWatch = context.WithCancel(context.Background())
+		fsEvents = startFSWatch(watchCtx, nextSources)
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			reload()
+		case _, ok := <-fsEvents:
+			if !ok {
+				fsEvents = nil
+				continue
+			}
+			reload()
+		}
+	}
+}
+
+// serveWatchHTTP exposes the current merged config and a forced-reload
+// trigger over plain HTTP, so StreamerBot or an operator can pull state
+// or force a reload without restarting the process.
+func serveWatchHTTP(state *watchState) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		c, _ := state.get()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c)
+	})
+
+	mux.HandleFunc("/reload", func(w http.ResponseWriter, r *http.Request) {
+		current, _ := state.get()
+		next, nextSources := buildMergedModelConfig()
+
+		flipped := diffBools(current, next)
+		if len(flipped) > 0 {
+			slog.Info("Redeems changed on forced reload: " + strings.Join(flipped, ", "))
+		}
+
+		writeMergedModelConfig(next)
+		state.set(next, nextSources)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(next)
+	})
+
+	slog.Info("Serving --watch HTTP endpoints on " + *watchAddr)
+	if err := http.ListenAndServe(*watchAddr, mux); err != nil {
+		slog.Error("watch HTTP server error: " + err.Error())
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// startFSWatch watches every directory containing one of sources with
+// fsnotify, and returns a channel that receives a signal as soon as any of
+// them changes. watchAndReload selects on this alongside its mtime poll
+// ticker so a real edit is picked up immediately instead of waiting out
+// watchPollInterval. The poll loop stays in place regardless of this
+// watch's success: it's the fallback for filesystems fsnotify can't see
+// (some network/FUSE mounts) and for directories that don't exist yet.
+// Returns nil if the watch can't be set up at all, in which case the
+// caller just keeps polling as before.
+func startFSWatch(ctx context.Context, sources []string) <-chan struct{} {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Debug("fsnotify unavailable, --watch will rely on polling: " + err.Error())
+		return nil
+	}
+
+	seen := map[string]bool{}
+	watched := 0
+	for _, src := range sources {
+		dir := filepath.Dir(src)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		if err := watcher.Add(dir); err != nil {
+			slog.Debug("fsnotify watch failed for " + dir + ": " + err.Error())
+			continue
+		}
+		watched++
+	}
+	if watched == 0 {
+		watcher.Close()
+		return nil
+	}
+
+	out := make(chan struct{}, 1)
+
+	go func() {
+		defer watcher.Close()
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Collapse a burst of events (e.g. a rename touching
+				// several watched names at once) into a single wake-up;
+				// the reload logic re-checks mtimes itself anyway.
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Debug("fsnotify error: " + err.Error())
+			}
+		}
+	}()
+
+	return out
+}